@@ -0,0 +1,3 @@
+// Solver for which words can be formed from a rack of tiles, as in anagrams or Bananagrams,
+// backed by an index of the letter counts of every word of a word source
+package anagram
@@ -0,0 +1,177 @@
+package anagram
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/digitaltembo/motli/packages/corpus/sources"
+)
+
+// Index of words grouped by their letters, so that all anagrams of each other are checked against a
+// rack at once, with a bitmask of the letters of each group to quickly rule out most groups
+type Index struct {
+	// groups of anagrams, sorted by length
+	groups []group
+}
+
+// Words which are all anagrams of each other
+type group struct {
+	// the letters of the words, sorted
+	letters []rune
+	// distinct letters of the words, and how many times each occurs
+	counts []letterCount
+	// bit letterBit(r) is set for every letter r of the words
+	mask  uint64
+	words []string
+}
+
+type letterCount struct {
+	letter rune
+	count  int
+}
+
+// Options restricting which words a rack can form
+type Options struct {
+	// Only include words which use every tile of the rack
+	Exact bool
+	// Only include words with at least this many letters, if positive
+	MinLength int
+	// Only include words with at most this many letters, if positive
+	MaxLength int
+}
+
+// Indexes every word of the word source
+func NewIndex(ws sources.WordSource) *Index {
+	words := []string{}
+	for _, w := range ws.GetWordList() {
+		words = append(words, w.Word)
+	}
+	return NewIndexFromWords(words)
+}
+
+// Indexes the words, lower-casing them
+func NewIndexFromWords(words []string) *Index {
+	bySignature := map[string]*group{}
+	for _, word := range words {
+		word = strings.ToLower(word)
+		if word == "" {
+			continue
+		}
+		letters := []rune(word)
+		slices.Sort(letters)
+		signature := string(letters)
+		g, ok := bySignature[signature]
+		if !ok {
+			g = &group{letters: letters}
+			for _, r := range letters {
+				g.mask |= letterBit(r)
+				if n := len(g.counts); n > 0 && g.counts[n-1].letter == r {
+					g.counts[n-1].count++
+				} else {
+					g.counts = append(g.counts, letterCount{letter: r, count: 1})
+				}
+			}
+			bySignature[signature] = g
+		}
+		if !slices.Contains(g.words, word) {
+			g.words = append(g.words, word)
+		}
+	}
+
+	ix := Index{}
+	for _, g := range bySignature {
+		slices.Sort(g.words)
+		ix.groups = append(ix.groups, *g)
+	}
+	slices.SortFunc(ix.groups, func(a, b group) int {
+		if len(a.letters) != len(b.letters) {
+			return len(a.letters) - len(b.letters)
+		}
+		return strings.Compare(string(a.letters), string(b.letters))
+	})
+	return &ix
+}
+
+// Every indexed word that can be formed from the rack, sorted by length and then alphabetically
+func (ix *Index) Solve(rack Rack, opts Options) []string {
+	words := []string{}
+	ix.visit(rack, opts, func(word string) {
+		words = append(words, word)
+	})
+	slices.SortFunc(words, func(a, b string) int {
+		return cmp.Or(cmp.Compare(utf8.RuneCountInString(a), utf8.RuneCountInString(b)), strings.Compare(a, b))
+	})
+	return words
+}
+
+// Number of indexed words that can be formed from the rack
+func (ix *Index) Count(rack Rack, opts Options) int {
+	count := 0
+	ix.visit(rack, opts, func(string) { count++ })
+	return count
+}
+
+// Calls found with every indexed word that can be formed from the rack
+func (ix *Index) visit(rack Rack, opts Options, found func(string)) {
+	// letters available from single-letter tiles, and (as an upper bound) from multi-letter tiles
+	supply := map[rune]int{}
+	var mask uint64
+	letterCapacity := 0
+	for tile, count := range rack {
+		if count <= 0 || tile == Blank {
+			continue
+		}
+		for _, r := range tile {
+			supply[r] += count
+			mask |= letterBit(r)
+			letterCapacity += count
+		}
+	}
+	blanks := rack[Blank]
+	letterCapacity += blanks
+	multi := rack.hasMultiLetterTiles()
+	maxTileLength := rack.maxTileLength()
+
+	// work on a copy, as forming words temporarily removes tiles
+	rack = Rack(maps.Clone(rack))
+	for _, g := range ix.groups {
+		length := len(g.letters)
+		if length > letterCapacity || (opts.MaxLength > 0 && length > opts.MaxLength) {
+			break
+		}
+		if opts.MinLength > 0 && length < opts.MinLength {
+			continue
+		}
+		if blanks == 0 && g.mask&^mask != 0 {
+			continue
+		}
+		missing := 0
+		for _, lc := range g.counts {
+			missing += max(0, lc.count-supply[lc.letter])
+		}
+		if missing > blanks {
+			continue
+		}
+
+		if !multi && !opts.Exact {
+			for _, word := range g.words {
+				found(word)
+			}
+			continue
+		}
+		for _, word := range g.words {
+			if rack.canForm([]rune(word), maxTileLength, opts.Exact) {
+				found(word)
+			}
+		}
+	}
+}
+
+// Bit of a 64-bit mask used for the letter. Letters beyond the 64th share bits with other letters,
+// which only makes the mask a less precise filter
+func letterBit(r rune) uint64 {
+	return 1 << (uint(r) % 64)
+}
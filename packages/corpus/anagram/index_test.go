@@ -0,0 +1,65 @@
+package anagram
+
+import (
+	"slices"
+	"testing"
+)
+
+var testWords = []string{"cab", "bad", "ace", "dab", "a", "Be", "bead", "quit", "quite", "queue", "tie"}
+
+func TestSolve(t *testing.T) {
+	ix := NewIndexFromWords(testWords)
+	cases := []struct {
+		name string
+		rack string
+		opts Options
+		want []string
+	}{
+		{"by length then alphabetically", "abcde", Options{}, []string{"a", "be", "ace", "bad", "cab", "dab", "bead"}},
+		{"blank", "ab?", Options{}, []string{"a", "be", "bad", "cab", "dab"}},
+		{"blank as underscore", "ce_", Options{}, []string{"a", "be", "ace"}},
+		{"single letter tiles", "q,u,i,t,e", Options{}, []string{"tie", "quit", "quite"}},
+		{"multi-letter tile", "qu,i,t,e", Options{}, []string{"tie", "quit", "quite"}},
+		{"multi-letter tile only as a whole", "qu,e,e,e", Options{}, []string{}},
+		{"exact", "b,a,d,e", Options{Exact: true}, []string{"bead"}},
+		{"exact multi-letter tile", "qu,i,t,e", Options{Exact: true}, []string{"quite"}},
+		{"exact blank", "?,i,e", Options{Exact: true}, []string{"tie"}},
+		{"min length", "abcde", Options{MinLength: 3}, []string{"ace", "bad", "cab", "dab", "bead"}},
+		{"max length", "abcde", Options{MaxLength: 2}, []string{"a", "be"}},
+		{"length bounds", "abcde", Options{MinLength: 3, MaxLength: 3}, []string{"ace", "bad", "cab", "dab"}},
+	}
+	for _, c := range cases {
+		rack := ParseRack(c.rack)
+		got := ix.Solve(rack, c.opts)
+		if !slices.Equal(got, c.want) {
+			t.Errorf("%s: Solve(%q) = %v, want %v", c.name, c.rack, got, c.want)
+		}
+		if count := ix.Count(rack, c.opts); count != len(c.want) {
+			t.Errorf("%s: Count(%q) = %d, want %d", c.name, c.rack, count, len(c.want))
+		}
+	}
+}
+
+func TestParseRack(t *testing.T) {
+	cases := []struct {
+		rack string
+		want Rack
+	}{
+		{"aab", Rack{"a": 2, "b": 1}},
+		{"A_?", Rack{"a": 1, Blank: 2}},
+		{"qu,e, th", Rack{"qu": 1, "e": 1, "th": 1}},
+	}
+	for _, c := range cases {
+		got := ParseRack(c.rack)
+		if len(got) != len(c.want) {
+			t.Errorf("ParseRack(%q) = %v, want %v", c.rack, got, c.want)
+			continue
+		}
+		for tile, count := range c.want {
+			if got[tile] != count {
+				t.Errorf("ParseRack(%q) = %v, want %v", c.rack, got, c.want)
+				break
+			}
+		}
+	}
+}
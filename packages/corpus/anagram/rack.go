@@ -0,0 +1,92 @@
+package anagram
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tile which can stand in for any single letter
+const Blank = "?"
+
+// Multiset of tiles, mapping each tile to the number of copies of it. Tiles are lower-case
+// and may have multiple letters (e.g. "qu"), and Blank stands in for any single letter
+type Rack map[string]int
+
+// Parses a rack from a list of tiles separated by commas or spaces (e.g. "a,e,qu,?"), or if
+// there are no separators, treats every character as its own tile (e.g. "aeq?"). "_" is also
+// accepted as a blank
+func ParseRack(s string) Rack {
+	rack := Rack{}
+	var tiles []string
+	if strings.ContainsAny(s, ", ") {
+		tiles = strings.FieldsFunc(s, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+	} else {
+		tiles = strings.Split(s, "")
+	}
+	for _, tile := range tiles {
+		tile = strings.ToLower(tile)
+		if tile == "_" {
+			tile = Blank
+		}
+		rack[tile]++
+	}
+	return rack
+}
+
+// Total number of tiles in the rack
+func (r Rack) Size() int {
+	size := 0
+	for _, count := range r {
+		size += count
+	}
+	return size
+}
+
+// Whether the rack has any tiles of more than one letter
+func (r Rack) hasMultiLetterTiles() bool {
+	for tile, count := range r {
+		if count > 0 && tile != Blank && utf8.RuneCountInString(tile) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// Number of letters in the longest tile of the rack
+func (r Rack) maxTileLength() int {
+	longest := 1
+	for tile, count := range r {
+		if count > 0 && tile != Blank {
+			longest = max(longest, utf8.RuneCountInString(tile))
+		}
+	}
+	return longest
+}
+
+// Whether the word can be spelled out of the tiles of the rack, using each tile at most once
+// (and every tile, if exact). Tiles may have multiple letters (up to maxTileLength), and
+// blanks stand in for any single letter. The rack is restored before returning
+func (r Rack) canForm(word []rune, maxTileLength int, exact bool) bool {
+	if len(word) == 0 {
+		return !exact || r.Size() == 0
+	}
+	for length := min(len(word), maxTileLength); length > 0; length-- {
+		tile := string(word[:length])
+		if r[tile] > 0 {
+			r[tile]--
+			formed := r.canForm(word[length:], maxTileLength, exact)
+			r[tile]++
+			if formed {
+				return true
+			}
+		}
+	}
+	if r[Blank] > 0 {
+		r[Blank]--
+		formed := r.canForm(word[1:], maxTileLength, exact)
+		r[Blank]++
+		return formed
+	}
+	return false
+}
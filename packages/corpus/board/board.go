@@ -0,0 +1,204 @@
+package board
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Bonus of a square of the board, which applies to the tile first placed on it
+type Premium int
+
+const (
+	Premium_None Premium = iota
+	// Doubles the score of the letter placed on the square
+	Premium_DoubleLetter
+	// Triples the score of the letter placed on the square
+	Premium_TripleLetter
+	// Doubles the score of the word through the square
+	Premium_DoubleWord
+	// Triples the score of the word through the square
+	Premium_TripleWord
+)
+
+// Multiplier of the score of a letter placed on the square
+func (p Premium) letterMultiplier() int {
+	switch p {
+	case Premium_DoubleLetter:
+		return 2
+	case Premium_TripleLetter:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// Multiplier of the score of a word through the square, when a tile is placed on it
+func (p Premium) wordMultiplier() int {
+	switch p {
+	case Premium_DoubleWord:
+		return 2
+	case Premium_TripleWord:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// Characters of a board layout, where "*" is the (double word) square the first move must cover
+var layoutPremiums = map[rune]Premium{
+	'.': Premium_None,
+	'd': Premium_DoubleLetter,
+	't': Premium_TripleLetter,
+	'D': Premium_DoubleWord,
+	'T': Premium_TripleWord,
+	'*': Premium_DoubleWord,
+}
+
+// Layout of the 15x15 Scrabble board
+var StandardLayout = []string{
+	"T..d...T...d..T",
+	".D...t...t...D.",
+	"..D...d.d...D..",
+	"d..D...d...D..d",
+	"....D.....D....",
+	".t...t...t...t.",
+	"..d...d.d...d..",
+	"T..d...*...d..T",
+	"..d...d.d...d..",
+	".t...t...t...t.",
+	"....D.....D....",
+	"d..D...d...D..d",
+	"..D...d.d...D..",
+	".D...t...t...D.",
+	"T..d...T...d..T",
+}
+
+// A tile on the board. The zero Tile is an empty square
+type Tile struct {
+	// Lower-case letter of the tile, or the letter a blank stands for
+	Letter rune
+	// Whether the tile is a blank, which scores no points
+	Blank bool
+}
+
+// Whether there is no tile
+func (t Tile) Empty() bool {
+	return t.Letter == 0
+}
+
+// Grid of premium squares and the tiles placed on them
+type Board struct {
+	Width  int
+	Height int
+	// Column and row of the square the first move must cover
+	StartX int
+	StartY int
+	// premium of each square in row-major order
+	premiums []Premium
+	// tile on each square in row-major order
+	tiles []Tile
+	// number of tiles placed
+	placed int
+}
+
+// Creates an empty board from a layout of rows of squares, each "." for a plain square, "d" or "t"
+// for a double or triple letter score, "D" or "T" for a double or triple word score, and a single "*"
+// for the double word square the first move must cover
+func NewBoard(layout []string) (*Board, error) {
+	if len(layout) == 0 {
+		return nil, fmt.Errorf("layout has no rows")
+	}
+	b := Board{Width: len([]rune(layout[0])), Height: len(layout), StartX: -1}
+	for y, row := range layout {
+		if len([]rune(row)) != b.Width {
+			return nil, fmt.Errorf("row %d of the layout has %d squares, expected %d", y, len([]rune(row)), b.Width)
+		}
+		for x, square := range []rune(row) {
+			premium, ok := layoutPremiums[square]
+			if !ok {
+				return nil, fmt.Errorf("invalid square %q in the layout", square)
+			}
+			if square == '*' {
+				if b.StartX >= 0 {
+					return nil, fmt.Errorf("layout has more than one start square")
+				}
+				b.StartX, b.StartY = x, y
+			}
+			b.premiums = append(b.premiums, premium)
+		}
+	}
+	if b.StartX < 0 {
+		return nil, fmt.Errorf("layout has no start square")
+	}
+	b.tiles = make([]Tile, len(b.premiums))
+	return &b, nil
+}
+
+// An empty 15x15 Scrabble board
+func Standard() *Board {
+	b, _ := NewBoard(StandardLayout)
+	return b
+}
+
+// Whether column x and row y is on the board
+func (b *Board) Contains(x int, y int) bool {
+	return x >= 0 && x < b.Width && y >= 0 && y < b.Height
+}
+
+// The premium of the square at column x and row y
+func (b *Board) Premium(x int, y int) Premium {
+	return b.premiums[y*b.Width+x]
+}
+
+// The tile at column x and row y, which is empty off the board
+func (b *Board) Tile(x int, y int) Tile {
+	if !b.Contains(x, y) {
+		return Tile{}
+	}
+	return b.tiles[y*b.Width+x]
+}
+
+// Whether no tiles have been placed
+func (b *Board) IsEmpty() bool {
+	return b.placed == 0
+}
+
+// Places the tiles of the move on the board
+func (b *Board) Play(move Move) {
+	for _, p := range move.Tiles {
+		b.tiles[p.Y*b.Width+p.X] = Tile{Letter: p.Letter, Blank: p.Blank}
+		b.placed++
+	}
+}
+
+// Copy of the board, which can be played on independently
+func (b *Board) Clone() *Board {
+	clone := *b
+	clone.tiles = slices.Clone(b.tiles)
+	return &clone
+}
+
+// One row of the board per line, with tiles in upper-case (or lower-case for blanks) and empty
+// squares as they are written in a layout
+func (b *Board) String() string {
+	var s strings.Builder
+	for y := range b.Height {
+		for x := range b.Width {
+			tile := b.Tile(x, y)
+			switch {
+			case !tile.Empty() && tile.Blank:
+				s.WriteRune(tile.Letter)
+			case !tile.Empty():
+				s.WriteRune(unicode.ToUpper(tile.Letter))
+			case x == b.StartX && y == b.StartY:
+				s.WriteRune('*')
+			default:
+				s.WriteByte(".dtDT"[b.Premium(x, y)])
+			}
+		}
+		s.WriteByte('\n')
+	}
+	return s.String()
+}
@@ -0,0 +1,4 @@
+// Scrabble-style boards of premium squares, racks of tiles, and a generator of every legal move
+// of a rack on a board along with its score, using the anchor and cross-check method of Appel and
+// Jacobson over the DAWG of a lexicon
+package board
@@ -0,0 +1,343 @@
+package board
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/digitaltembo/motli/packages/corpus/lexicon"
+)
+
+// Number of tiles which must be played at once for a bingo
+const BingoTiles = 7
+
+// Bonus points for playing a bingo
+const BingoBonus = 50
+
+// A tile placed on the board by a move
+type Placement struct {
+	X      int  `json:"x"`
+	Y      int  `json:"y"`
+	Letter rune `json:"letter"`
+	Blank  bool `json:"blank"`
+}
+
+// A legal play of tiles from a rack
+type Move struct {
+	// Word formed along the direction of the move
+	Word string `json:"word"`
+	// Column and row of the first letter of the word
+	X int `json:"x"`
+	Y int `json:"y"`
+	// Whether the word reads across rather than down
+	Horizontal bool `json:"horizontal"`
+	// Tiles placed from the rack, in the order they appear in the word
+	Tiles []Placement `json:"tiles"`
+	// Words formed across the direction of the move by the tiles placed
+	CrossWords []string `json:"crossWords"`
+	// Points scored by the word, the cross words and any bingo bonus
+	Score int `json:"score"`
+}
+
+// Whether the move uses enough tiles for the bingo bonus
+func (m Move) Bingo() bool {
+	return len(m.Tiles) >= BingoTiles
+}
+
+// Identifies the tiles placed by the move, which is the same for a single tile whichever direction is its word
+func (m Move) key() string {
+	var s strings.Builder
+	for _, p := range m.Tiles {
+		fmt.Fprintf(&s, "%d,%d,%c,%t;", p.X, p.Y, p.Letter, p.Blank)
+	}
+	return s.String()
+}
+
+// Every legal move of tiles from the rack onto the board, where the tiles form a contiguous word of at
+// least 2 letters of the lexicon along a row or column that covers the start square on an empty board
+// and otherwise touches a tile already on the board, and every word formed across it is also in the
+// lexicon. Letters score the points of the scores (keyed by lower-case letter), with blanks scoring
+// nothing. Moves are sorted from the highest to lowest score
+func Moves(b *Board, rack Rack, lex *lexicon.Lexicon, scores map[string]int) []Move {
+	g := generator{
+		board:  b,
+		lex:    lex,
+		rack:   Rack{},
+		scores: map[rune]int{},
+		seen:   map[string]bool{},
+	}
+	for tile, count := range rack {
+		g.rack[tile] = count
+	}
+	for letter, score := range scores {
+		if r, size := utf8.DecodeRuneInString(strings.ToLower(letter)); size == len(letter) {
+			g.scores[r] = score
+		}
+	}
+	for _, horizontal := range []bool{true, false} {
+		g.horizontal = horizontal
+		lines, length := b.Height, b.Width
+		if !horizontal {
+			lines, length = b.Width, b.Height
+		}
+		for line := range lines {
+			g.line, g.length = line, length
+			g.findMoves()
+		}
+	}
+	slices.SortStableFunc(g.moves, func(a, b Move) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return strings.Compare(a.key(), b.key())
+	})
+	return g.moves
+}
+
+// Letters which can be placed on a square given the tiles across the direction of the move
+type crossCheck struct {
+	// whether there are tiles next to the square across the direction of the move
+	constrained bool
+	// the letters forming a word of the lexicon with the tiles next to the square
+	allowed map[rune]bool
+	// the tiles before and after the square across the direction of the move
+	before []rune
+	after  []rune
+	// points of the tiles next to the square across the direction of the move
+	score int
+}
+
+// State of generating the moves along one line of the board
+type generator struct {
+	board  *Board
+	lex    *lexicon.Lexicon
+	rack   Rack
+	scores map[rune]int
+
+	horizontal bool
+	line       int
+	length     int
+	checks     []crossCheck
+
+	// the word being built, which squares of it are new tiles, and which new tiles are blanks
+	word   []rune
+	placed []bool
+	blanks []bool
+
+	moves []Move
+	seen  map[string]bool
+}
+
+// Column and row of the ith square along the current line
+func (g *generator) at(i int) (int, int) {
+	if g.horizontal {
+		return i, g.line
+	}
+	return g.line, i
+}
+
+// The tile on the ith square along the current line, which is empty off the board
+func (g *generator) tile(i int) Tile {
+	if i < 0 || i >= g.length {
+		return Tile{}
+	}
+	return g.board.Tile(g.at(i))
+}
+
+// The tiles in a row starting next to column x and row y, stepping by dx and dy
+func (g *generator) run(x int, y int, dx int, dy int) []Tile {
+	tiles := []Tile{}
+	for x, y = x+dx, y+dy; !g.board.Tile(x, y).Empty(); x, y = x+dx, y+dy {
+		tiles = append(tiles, g.board.Tile(x, y))
+	}
+	return tiles
+}
+
+func (g *generator) letterScore(tile Tile) int {
+	if tile.Blank {
+		return 0
+	}
+	return g.scores[tile.Letter]
+}
+
+// Finds the moves along the current line, from each anchor: an empty square next to a tile already
+// on the board (or the start square of an empty board)
+func (g *generator) findMoves() {
+	// across the line is down for a horizontal move, and across for a vertical one
+	dx, dy := 0, 1
+	if !g.horizontal {
+		dx, dy = 1, 0
+	}
+	g.checks = make([]crossCheck, g.length)
+	anchors := make([]bool, g.length)
+	for i := range g.length {
+		if !g.tile(i).Empty() {
+			continue
+		}
+		x, y := g.at(i)
+		before := g.run(x, y, -dx, -dy)
+		slices.Reverse(before)
+		after := g.run(x, y, dx, dy)
+		if len(before) > 0 || len(after) > 0 {
+			check := crossCheck{constrained: true, allowed: map[rune]bool{}}
+			for _, tile := range before {
+				check.before = append(check.before, tile.Letter)
+				check.score += g.letterScore(tile)
+			}
+			for _, tile := range after {
+				check.after = append(check.after, tile.Letter)
+				check.score += g.letterScore(tile)
+			}
+			if node, ok := g.lex.Dawg.Walk(g.lex.Dawg.Root(), check.before); ok {
+				for r, next := range g.lex.Dawg.Edges(node) {
+					if end, ok := g.lex.Dawg.Walk(next, check.after); ok && g.lex.Dawg.IsTerminal(end) {
+						check.allowed[r] = true
+					}
+				}
+			}
+			g.checks[i] = check
+		}
+		anchors[i] = len(before) > 0 || len(after) > 0 || !g.tile(i-1).Empty() || !g.tile(i+1).Empty() ||
+			(g.board.IsEmpty() && x == g.board.StartX && y == g.board.StartY)
+	}
+
+	for anchor := range g.length {
+		if !anchors[anchor] {
+			continue
+		}
+		if !g.tile(anchor - 1).Empty() {
+			// the word must start with the tiles already before the anchor
+			start := anchor - 1
+			for !g.tile(start - 1).Empty() {
+				start--
+			}
+			g.word, g.placed, g.blanks = nil, nil, nil
+			node := g.lex.Dawg.Root()
+			ok := true
+			for i := start; i < anchor && ok; i++ {
+				node, ok = g.lex.Dawg.Next(node, g.tile(i).Letter)
+				g.push(g.tile(i).Letter, false, false)
+			}
+			if ok {
+				g.extendRight(node, anchor, anchor)
+			}
+			continue
+		}
+		// the word can start with new tiles on the empty squares before the anchor, up to the previous anchor
+		limit := 0
+		for i := anchor - 1; i >= 0 && g.tile(i).Empty() && !anchors[i]; i-- {
+			limit++
+		}
+		g.word, g.placed, g.blanks = nil, nil, nil
+		g.leftPart(g.lex.Dawg.Root(), anchor, limit)
+	}
+}
+
+// Builds every prefix of up to limit tiles from the rack which ends right before the anchor,
+// extending each to the right through the anchor
+func (g *generator) leftPart(node lexicon.Node, anchor int, limit int) {
+	g.extendRight(node, anchor, anchor)
+	if limit == 0 {
+		return
+	}
+	for r, next := range g.lex.Dawg.Edges(node) {
+		g.withTile(r, func() {
+			g.leftPart(next, anchor, limit-1)
+		})
+	}
+}
+
+// Extends the word from the ith square along the line, recording it as a move wherever it forms a
+// word of the lexicon covering the anchor
+func (g *generator) extendRight(node lexicon.Node, i int, anchor int) {
+	tile := g.tile(i)
+	if !tile.Empty() {
+		if next, ok := g.lex.Dawg.Next(node, tile.Letter); ok {
+			g.push(tile.Letter, false, false)
+			g.extendRight(next, i+1, anchor)
+			g.pop()
+		}
+		return
+	}
+	if i > anchor && len(g.word) >= 2 && g.lex.Dawg.IsTerminal(node) {
+		g.record(i)
+	}
+	if i >= g.length {
+		return
+	}
+	for r, next := range g.lex.Dawg.Edges(node) {
+		if check := g.checks[i]; check.constrained && !check.allowed[r] {
+			continue
+		}
+		g.withTile(r, func() {
+			g.extendRight(next, i+1, anchor)
+		})
+	}
+}
+
+// Calls extend with the letter pushed onto the word as a new tile, once using a tile of the letter
+// from the rack if there is one, and once using a blank if there is one
+func (g *generator) withTile(letter rune, extend func()) {
+	for _, blank := range []bool{false, true} {
+		tile := letter
+		if blank {
+			tile = Blank
+		}
+		if g.rack[tile] == 0 {
+			continue
+		}
+		g.rack[tile]--
+		g.push(letter, true, blank)
+		extend()
+		g.pop()
+		g.rack[tile]++
+	}
+}
+
+func (g *generator) push(letter rune, placed bool, blank bool) {
+	g.word = append(g.word, letter)
+	g.placed = append(g.placed, placed)
+	g.blanks = append(g.blanks, blank)
+}
+
+func (g *generator) pop() {
+	g.word = g.word[:len(g.word)-1]
+	g.placed = g.placed[:len(g.placed)-1]
+	g.blanks = g.blanks[:len(g.blanks)-1]
+}
+
+// Scores the word ending before the ith square along the line, and adds it to the moves
+func (g *generator) record(end int) {
+	start := end - len(g.word)
+	x, y := g.at(start)
+	move := Move{Word: string(g.word), X: x, Y: y, Horizontal: g.horizontal, CrossWords: []string{}}
+	wordScore, wordMultiplier, crossScores := 0, 1, 0
+	for k, letter := range g.word {
+		i := start + k
+		tile := Tile{Letter: letter, Blank: g.blanks[k]}
+		if !g.placed[k] {
+			wordScore += g.letterScore(g.tile(i))
+			continue
+		}
+		tx, ty := g.at(i)
+		premium := g.board.Premium(tx, ty)
+		letterScore := g.letterScore(tile) * premium.letterMultiplier()
+		wordScore += letterScore
+		wordMultiplier *= premium.wordMultiplier()
+		move.Tiles = append(move.Tiles, Placement{X: tx, Y: ty, Letter: letter, Blank: tile.Blank})
+		if check := g.checks[i]; check.constrained {
+			move.CrossWords = append(move.CrossWords, string(slices.Concat(check.before, []rune{letter}, check.after)))
+			crossScores += (check.score + letterScore) * premium.wordMultiplier()
+		}
+	}
+	move.Score = wordScore*wordMultiplier + crossScores
+	if move.Bingo() {
+		move.Score += BingoBonus
+	}
+	if key := move.key(); !g.seen[key] {
+		g.seen[key] = true
+		g.moves = append(g.moves, move)
+	}
+}
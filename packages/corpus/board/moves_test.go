@@ -0,0 +1,162 @@
+package board
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/digitaltembo/motli/packages/corpus/lexicon"
+)
+
+var testLexicon = lexicon.BuildFromWords([]string{"ab", "at", "bo", "cab", "cabs", "cat", "ta", "to", "cabbage"})
+
+var testScores = map[string]int{"a": 1, "b": 3, "c": 3, "e": 1, "g": 2, "o": 1, "s": 1, "t": 1}
+
+// Layout with premium squares under and beside the start square
+var testLayout = []string{
+	".....",
+	".....",
+	"Dt*D.",
+	".d...",
+	".....",
+}
+
+// The 5x5 test board, with "cab" already played across the middle row
+func testBoardWithCab(t *testing.T) *Board {
+	t.Helper()
+	b, err := NewBoard(testLayout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Play(Move{Tiles: []Placement{{X: 0, Y: 2, Letter: 'c'}, {X: 1, Y: 2, Letter: 'a'}, {X: 2, Y: 2, Letter: 'b'}}})
+	return b
+}
+
+func testMoves(t *testing.T, b *Board, rack string) []Move {
+	t.Helper()
+	r, err := ParseRack(rack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Moves(b, r, testLexicon, testScores)
+}
+
+// The move of the word starting at column x and row y in the direction, if there is one
+func findMove(moves []Move, word string, x int, y int, horizontal bool) *Move {
+	for _, move := range moves {
+		if move.Word == word && move.X == x && move.Y == y && move.Horizontal == horizontal {
+			return &move
+		}
+	}
+	return nil
+}
+
+func TestFirstMoveCoversStart(t *testing.T) {
+	b, err := NewBoard(testLayout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	moves := testMoves(t, b, "cabt")
+	if len(moves) == 0 {
+		t.Fatal("no moves on an empty board")
+	}
+	for _, move := range moves {
+		covers := slices.ContainsFunc(move.Tiles, func(p Placement) bool {
+			return p.X == b.StartX && p.Y == b.StartY
+		})
+		if !covers {
+			t.Errorf("first move %s at %d,%d does not cover the start square", move.Word, move.X, move.Y)
+		}
+	}
+	if move := findMove(moves, "cab", 0, 2, true); move == nil || move.Score != 36 {
+		t.Errorf("cab across the start row is %+v, want a score of (3+1*3+3)*2*2 = 36", move)
+	}
+	if move := findMove(moves, "cab", 2, 0, false); move == nil || move.Score != 14 {
+		t.Errorf("cab down to the start square is %+v, want a score of (3+1+3)*2 = 14", move)
+	}
+}
+
+func TestMoveScores(t *testing.T) {
+	empty, err := NewBoard(testLayout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	standard := Standard()
+	cases := []struct {
+		name       string
+		board      *Board
+		rack       string
+		word       string
+		x          int
+		y          int
+		horizontal bool
+		score      int
+		crossWords []string
+		bingo      bool
+	}{
+		// the blank stands for the a on the triple letter square
+		{"blank scores nothing", empty, "c?b", "cab", 0, 2, true, (3 + 0 + 3) * 2 * 2, []string{}, false},
+		// the premiums under c, a and b were used up when they were played
+		{"premiums of new tiles only", testBoardWithCab(t), "s", "cabs", 0, 2, true, (3 + 1 + 3 + 1) * 2, []string{}, false},
+		// t on the double letter square counts double in "to" and in "at"
+		{"cross words", testBoardWithCab(t), "ot", "to", 1, 3, true, (1*2 + 1) + (1 + 1*2) + (3 + 1), []string{"at", "bo"}, false},
+		{"down through a played tile", testBoardWithCab(t), "t", "at", 1, 2, false, 1 + 1*2, []string{}, false},
+		{"bingo", standard, "cabbage", "cabbage", 1, 7, true, (3+1+3*2+3+1+2+1)*2 + BingoBonus, []string{}, true},
+	}
+	for _, c := range cases {
+		move := findMove(testMoves(t, c.board, c.rack), c.word, c.x, c.y, c.horizontal)
+		if move == nil {
+			t.Errorf("%s: no move %s at %d,%d", c.name, c.word, c.x, c.y)
+			continue
+		}
+		if move.Score != c.score {
+			t.Errorf("%s: %s scores %d, want %d", c.name, c.word, move.Score, c.score)
+		}
+		if !slices.Equal(move.CrossWords, c.crossWords) {
+			t.Errorf("%s: %s forms cross words %v, want %v", c.name, c.word, move.CrossWords, c.crossWords)
+		}
+		if move.Bingo() != c.bingo {
+			t.Errorf("%s: %s with %d tiles is a bingo: %t, want %t", c.name, c.word, len(move.Tiles), move.Bingo(), c.bingo)
+		}
+	}
+}
+
+func TestBlankPlacement(t *testing.T) {
+	b, err := NewBoard(testLayout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	move := findMove(testMoves(t, b, "c?b"), "cab", 0, 2, true)
+	if move == nil {
+		t.Fatal("no move cab")
+	}
+	want := []Placement{{X: 0, Y: 2, Letter: 'c'}, {X: 1, Y: 2, Letter: 'a', Blank: true}, {X: 2, Y: 2, Letter: 'b'}}
+	if !slices.Equal(move.Tiles, want) {
+		t.Errorf("cab places %+v, want %+v", move.Tiles, want)
+	}
+}
+
+func TestInvalidCrossWordRejected(t *testing.T) {
+	b := testBoardWithCab(t)
+	moves := testMoves(t, b, "ta")
+	// "ta" under "ab" would form "at" and "ba", which is not a word
+	if move := findMove(moves, "ta", 1, 3, true); move != nil {
+		t.Errorf("played %+v forming an invalid cross word", move)
+	}
+	for _, move := range moves {
+		for _, word := range move.CrossWords {
+			if !testLexicon.Contains(word) {
+				t.Errorf("%s at %d,%d forms the invalid cross word %s", move.Word, move.X, move.Y, word)
+			}
+		}
+	}
+}
+
+func TestMovesSortedByScore(t *testing.T) {
+	moves := testMoves(t, testBoardWithCab(t), "?ots")
+	if len(moves) == 0 {
+		t.Fatal("no moves")
+	}
+	if !slices.IsSortedFunc(moves, func(a, b Move) int { return b.Score - a.Score }) {
+		t.Errorf("moves are not sorted from highest to lowest score")
+	}
+}
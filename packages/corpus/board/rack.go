@@ -0,0 +1,66 @@
+package board
+
+import (
+	"fmt"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/digitaltembo/motli/packages/corpus/anagram"
+)
+
+// Blank tile of a rack, which can stand for any letter
+const Blank = '?'
+
+// Tiles of a rack, mapping each lower-case letter (or Blank) to the number of copies of it
+type Rack map[rune]int
+
+// Parses a rack as accepted by anagram.ParseRack, e.g. "aeqt?", where every tile must be a single letter
+func ParseRack(s string) (Rack, error) {
+	rack := Rack{}
+	for tile, count := range anagram.ParseRack(s) {
+		if tile == anagram.Blank {
+			rack[Blank] += count
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(tile)
+		if size != len(tile) {
+			return nil, fmt.Errorf("multi-letter tile %q cannot be played on a board", tile)
+		}
+		rack[r] += count
+	}
+	return rack, nil
+}
+
+// Number of tiles in the rack
+func (r Rack) Size() int {
+	size := 0
+	for _, count := range r {
+		size += count
+	}
+	return size
+}
+
+// Every tile of the rack in sorted order, with blanks first
+func (r Rack) Tiles() []rune {
+	tiles := []rune{}
+	for tile, count := range r {
+		for range count {
+			tiles = append(tiles, tile)
+		}
+	}
+	slices.Sort(tiles)
+	return tiles
+}
+
+// Takes the tiles placed by the move out of the rack
+func (r Rack) Remove(move Move) {
+	for _, p := range move.Tiles {
+		tile := p.Letter
+		if p.Blank {
+			tile = Blank
+		}
+		if r[tile]--; r[tile] <= 0 {
+			delete(r, tile)
+		}
+	}
+}
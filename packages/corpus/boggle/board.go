@@ -0,0 +1,131 @@
+package boggle
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math/rand"
+	"os"
+	"slices"
+	"strings"
+)
+
+// A die, as the letters on each of its faces. Faces may have multiple letters, like "qu"
+type Die []string
+
+// The 16 dice of (post-1987) New Boggle
+var ClassicDice = []Die{
+	{"a", "a", "e", "e", "g", "n"},
+	{"a", "b", "b", "j", "o", "o"},
+	{"a", "c", "h", "o", "p", "s"},
+	{"a", "f", "f", "k", "p", "s"},
+	{"a", "o", "o", "t", "t", "w"},
+	{"c", "i", "m", "o", "t", "u"},
+	{"d", "e", "i", "l", "r", "x"},
+	{"d", "e", "l", "r", "v", "y"},
+	{"d", "i", "s", "t", "t", "y"},
+	{"e", "e", "g", "h", "n", "w"},
+	{"e", "e", "i", "n", "s", "u"},
+	{"e", "h", "r", "t", "v", "w"},
+	{"e", "i", "o", "s", "s", "t"},
+	{"e", "l", "r", "t", "t", "y"},
+	{"h", "i", "m", "n", "qu", "u"},
+	{"h", "l", "n", "n", "r", "z"},
+}
+
+// Grid of faces showing on a Boggle board
+type Board struct {
+	Width  int
+	Height int
+	// faces of the board in row-major order, lower-case
+	Faces []string
+}
+
+// The face at column x and row y
+func (b *Board) Face(x int, y int) string {
+	return b.Faces[y*b.Width+x]
+}
+
+// Faces of the board as rows
+func (b *Board) Rows() [][]string {
+	rows := [][]string{}
+	for y := range b.Height {
+		rows = append(rows, b.Faces[y*b.Width:(y+1)*b.Width])
+	}
+	return rows
+}
+
+// One row of the board per line, with multi-letter faces capitalized like "Qu"
+func (b *Board) String() string {
+	var s strings.Builder
+	for _, row := range b.Rows() {
+		cells := []string{}
+		for _, face := range row {
+			runes := []rune(face)
+			cells = append(cells, fmt.Sprintf("%-2s", strings.ToUpper(string(runes[:1]))+string(runes[1:])))
+		}
+		s.WriteString(strings.TrimRight(strings.Join(cells, " "), " "))
+		s.WriteByte('\n')
+	}
+	return s.String()
+}
+
+// Shuffles the dice into the positions of a width by height board and rolls each of them.
+// There must be exactly one die per position
+func RollDice(rng *rand.Rand, dice []Die, width int, height int) (*Board, error) {
+	if len(dice) != width*height {
+		return nil, fmt.Errorf("need %d dice for a %dx%d board, have %d", width*height, width, height, len(dice))
+	}
+	board := Board{Width: width, Height: height}
+	for _, i := range rng.Perm(len(dice)) {
+		if len(dice[i]) == 0 {
+			return nil, fmt.Errorf("die %d has no faces", i)
+		}
+		if slices.Contains(dice[i], "") {
+			return nil, fmt.Errorf("die %d has an empty face", i)
+		}
+		face := dice[i][rng.Intn(len(dice[i]))]
+		board.Faces = append(board.Faces, strings.ToLower(face))
+	}
+	return &board, nil
+}
+
+// Draws the faces of a width by height board without replacement from a set of tiles,
+// mapping each tile to the number of copies of it (as written by processes.TileSet)
+func DrawTiles(rng *rand.Rand, tiles map[string]int, width int, height int) (*Board, error) {
+	bag := []string{}
+	for _, tile := range slices.Sorted(maps.Keys(tiles)) {
+		if tile == "" && tiles[tile] > 0 {
+			return nil, fmt.Errorf("tiles include an empty tile")
+		}
+		for range tiles[tile] {
+			bag = append(bag, strings.ToLower(tile))
+		}
+	}
+	if len(bag) < width*height {
+		return nil, fmt.Errorf("need %d tiles for a %dx%d board, have %d", width*height, width, height, len(bag))
+	}
+	board := Board{Width: width, Height: height}
+	for _, i := range rng.Perm(len(bag))[:width*height] {
+		board.Faces = append(board.Faces, bag[i])
+	}
+	return &board, nil
+}
+
+// Reads dice from a JSON file of an array of dice, each an array of faces
+func ReadDice(file string) ([]Die, error) {
+	contents, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	dice := []Die{}
+	if err := json.Unmarshal(contents, &dice); err != nil {
+		return nil, err
+	}
+	for i, die := range dice {
+		if slices.Contains(die, "") {
+			return nil, fmt.Errorf("die %d of %s has an empty face", i, file)
+		}
+	}
+	return dice, nil
+}
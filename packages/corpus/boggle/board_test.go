@@ -0,0 +1,47 @@
+package boggle
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBoardString(t *testing.T) {
+	b := Board{Width: 2, Height: 2, Faces: []string{"a", "qu", "th", "e"}}
+	if got, want := b.String(), "A  Qu\nTh E\n"; got != want {
+		t.Errorf("board is %q, want %q", got, want)
+	}
+}
+
+func TestEmptyFacesRejected(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	if _, err := RollDice(rng, []Die{{"a", ""}}, 1, 1); err == nil {
+		t.Errorf("rolled a die with an empty face")
+	}
+	if _, err := DrawTiles(rng, map[string]int{"a": 1, "": 1}, 1, 1); err == nil {
+		t.Errorf("drew from tiles with an empty tile")
+	}
+
+	file := filepath.Join(t.TempDir(), "dice.json")
+	if err := os.WriteFile(file, []byte(`[["a", "b"], ["c", ""]]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadDice(file); err == nil {
+		t.Errorf("read dice with an empty face")
+	}
+}
+
+func TestRollDice(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	b, err := RollDice(rng, ClassicDice, 4, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Faces) != 16 || b.Width != 4 || b.Height != 4 {
+		t.Errorf("rolled a %dx%d board of %d faces", b.Width, b.Height, len(b.Faces))
+	}
+	if _, err := RollDice(rng, ClassicDice, 3, 3); err == nil {
+		t.Errorf("rolled 16 dice onto a 3x3 board")
+	}
+}
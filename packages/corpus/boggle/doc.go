@@ -0,0 +1,3 @@
+// Boggle boards, rolled from dice or drawn from a set of tiles, and a solver for every word
+// that can be traced through adjacent faces of a board
+package boggle
@@ -0,0 +1,81 @@
+package boggle
+
+import (
+	"slices"
+	"unicode/utf8"
+
+	"github.com/digitaltembo/motli/packages/corpus/lexicon"
+)
+
+// Minimum number of letters in a word found on a standard 4x4 board
+const MinLength = 3
+
+// Every word found on a board, along with their total points
+type Solution struct {
+	Words  []string `json:"words"`
+	Points int      `json:"points"`
+}
+
+// Points for a word of the given length under the standard rules
+func Score(word string) int {
+	switch length := utf8.RuneCountInString(word); {
+	case length < 3:
+		return 0
+	case length <= 4:
+		return 1
+	case length == 5:
+		return 2
+	case length == 6:
+		return 3
+	case length == 7:
+		return 5
+	default:
+		return 11
+	}
+}
+
+// Finds every word of the lexicon with at least minLength letters that can be traced through
+// horizontally, vertically or diagonally adjacent faces of the board without reusing a face,
+// pruning paths with the lexicon's DAWG as soon as they stop being the prefix of any word
+func Solve(board *Board, lex *lexicon.Lexicon, minLength int) Solution {
+	found := map[string]bool{}
+	visited := make([]bool, len(board.Faces))
+	dawg := lex.Dawg
+
+	var trace func(x int, y int, node lexicon.Node, word []rune)
+	trace = func(x int, y int, node lexicon.Node, word []rune) {
+		i := y*board.Width + x
+		node, ok := dawg.Walk(node, []rune(board.Faces[i]))
+		if !ok {
+			return
+		}
+		word = append(word, []rune(board.Faces[i])...)
+		if dawg.IsTerminal(node) && len(word) >= minLength {
+			found[string(word)] = true
+		}
+		visited[i] = true
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				nx, ny := x+dx, y+dy
+				if nx < 0 || ny < 0 || nx >= board.Width || ny >= board.Height || visited[ny*board.Width+nx] {
+					continue
+				}
+				trace(nx, ny, node, word)
+			}
+		}
+		visited[i] = false
+	}
+	for y := range board.Height {
+		for x := range board.Width {
+			trace(x, y, dawg.Root(), nil)
+		}
+	}
+
+	solution := Solution{Words: []string{}}
+	for word := range found {
+		solution.Words = append(solution.Words, word)
+		solution.Points += Score(word)
+	}
+	slices.Sort(solution.Words)
+	return solution
+}
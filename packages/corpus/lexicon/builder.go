@@ -0,0 +1,124 @@
+package lexicon
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Mutable node of a graph under construction
+type builderNode struct {
+	terminal bool
+	// edges out of the node, in the (sorted) order they were added
+	edges []builderEdge
+	// identifier assigned when the node is registered as minimized
+	id int
+}
+
+type builderEdge struct {
+	label rune
+	child *builderNode
+}
+
+// Edge from a node whose subgraph may not be minimized yet
+type uncheckedEdge struct {
+	parent *builderNode
+	child  *builderNode
+}
+
+// Builds a minimized acyclic graph out of words inserted in sorted order, using the incremental
+// algorithm of Daciuk et al. - after each insertion, every node no longer on the path of the most
+// recent word is either merged with an equivalent node already in the register or added to it
+type builder struct {
+	root      *builderNode
+	previous  []rune
+	unchecked []uncheckedEdge
+	register  map[string]*builderNode
+}
+
+func newBuilder() *builder {
+	return &builder{root: &builderNode{}, register: map[string]*builderNode{}}
+}
+
+// Adds the word to the graph. Words must be inserted in sorted order, without duplicates
+func (b *builder) insert(word []rune) {
+	common := 0
+	for common < len(word) && common < len(b.previous) && word[common] == b.previous[common] {
+		common++
+	}
+	b.minimize(common)
+
+	node := b.root
+	if len(b.unchecked) > 0 {
+		node = b.unchecked[len(b.unchecked)-1].child
+	}
+	for _, r := range word[common:] {
+		child := &builderNode{}
+		node.edges = append(node.edges, builderEdge{label: r, child: child})
+		b.unchecked = append(b.unchecked, uncheckedEdge{parent: node, child: child})
+		node = child
+	}
+	node.terminal = true
+	b.previous = word
+}
+
+// Merges or registers the unchecked nodes deeper than downTo
+func (b *builder) minimize(downTo int) {
+	for i := len(b.unchecked) - 1; i >= downTo; i-- {
+		u := b.unchecked[i]
+		signature := u.child.signature()
+		if existing, ok := b.register[signature]; ok {
+			// the child is always the most recently added edge of the parent
+			u.parent.edges[len(u.parent.edges)-1].child = existing
+		} else {
+			u.child.id = len(b.register) + 1
+			b.register[signature] = u.child
+		}
+	}
+	b.unchecked = b.unchecked[:downTo]
+}
+
+// Minimizes the rest of the graph and compiles it
+func (b *builder) finish() *Graph {
+	b.minimize(0)
+	return compile(b.root)
+}
+
+// Identifies the node by its terminality and the (already minimized) nodes its edges lead to,
+// so that equivalent nodes have the same signature
+func (n *builderNode) signature() string {
+	var s strings.Builder
+	if n.terminal {
+		s.WriteByte('!')
+	}
+	for _, e := range n.edges {
+		s.WriteString(strconv.Itoa(int(e.label)))
+		s.WriteByte(':')
+		s.WriteString(strconv.Itoa(e.child.id))
+		s.WriteByte(',')
+	}
+	return s.String()
+}
+
+// Flattens the graph under the root into a Graph, numbering nodes in breadth-first order
+func compile(root *builderNode) *Graph {
+	index := map[*builderNode]uint32{root: 0}
+	queue := []*builderNode{root}
+	g := Graph{}
+	for i := 0; i < len(queue); i++ {
+		node := queue[i]
+		g.first = append(g.first, uint32(len(g.labels)))
+		g.terminal = append(g.terminal, node.terminal)
+		for _, e := range node.edges {
+			target, ok := index[e.child]
+			if !ok {
+				target = uint32(len(queue))
+				index[e.child] = target
+				queue = append(queue, e.child)
+			}
+			g.labels = append(g.labels, e.label)
+			g.targets = append(g.targets, target)
+		}
+	}
+	g.first = append(g.first, uint32(len(g.labels)))
+	return &g
+}
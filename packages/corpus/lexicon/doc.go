@@ -0,0 +1,3 @@
+// Compiled lexicons of words for fast prefix and anchor lookups by game solvers,
+// as a minimized DAWG (directed acyclic word graph) and GADDAG
+package lexicon
@@ -0,0 +1,149 @@
+package lexicon
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Identifies (and versions) the binary lexicon format
+const magic = "MOTLILEX1"
+
+// Saves the lexicon as a compact binary file: the magic header, followed by the DAWG and then
+// the GADDAG, each encoded as uvarints: the number of nodes, then for each node its number of
+// edges shifted left by one with the lowest bit set if the node is terminal, then each edge's
+// label and target
+func (l *Lexicon) Save(file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if _, err := w.WriteString(magic); err != nil {
+		return err
+	}
+	for _, g := range []*Graph{l.Dawg, l.Gaddag} {
+		if err := g.write(w); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// Loads a lexicon saved by Lexicon.Save
+func Load(file string) (*Lexicon, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	r := &lexiconReader{r: bufio.NewReader(f), remaining: info.Size() - int64(len(magic))}
+	header := make([]byte, len(magic))
+	if _, err := io.ReadFull(r.r, header); err != nil || string(header) != magic {
+		return nil, fmt.Errorf("%s is not a lexicon file", file)
+	}
+	dawg, err := readGraph(r)
+	if err != nil {
+		return nil, err
+	}
+	gaddag, err := readGraph(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Lexicon{Dawg: dawg, Gaddag: gaddag}, nil
+}
+
+func (g *Graph) write(w *bufio.Writer) error {
+	buf := make([]byte, binary.MaxVarintLen64)
+	put := func(v uint64) error {
+		_, err := w.Write(buf[:binary.PutUvarint(buf, v)])
+		return err
+	}
+
+	if err := put(uint64(g.NodeCount())); err != nil {
+		return err
+	}
+	for n := range g.NodeCount() {
+		header := uint64(g.first[n+1]-g.first[n]) << 1
+		if g.terminal[n] {
+			header |= 1
+		}
+		if err := put(header); err != nil {
+			return err
+		}
+		for i := g.first[n]; i < g.first[n+1]; i++ {
+			if err := put(uint64(g.labels[i])); err != nil {
+				return err
+			}
+			if err := put(uint64(g.targets[i])); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Reader of the uvarints of a lexicon file, which tracks how many bytes of the file are left so
+// that counts read from a corrupt file can be rejected before allocating for them
+type lexiconReader struct {
+	r         *bufio.Reader
+	remaining int64
+}
+
+func (r *lexiconReader) ReadByte() (byte, error) {
+	b, err := r.r.ReadByte()
+	if err == nil {
+		r.remaining--
+	}
+	return b, err
+}
+
+func readGraph(r *lexiconReader) (*Graph, error) {
+	nodes, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	// every node takes at least a byte
+	if nodes > uint64(r.remaining) {
+		return nil, fmt.Errorf("corrupt lexicon: %d nodes in %d bytes", nodes, r.remaining)
+	}
+	g := Graph{terminal: make([]bool, nodes), first: make([]uint32, 0, nodes+1)}
+	for n := range nodes {
+		header, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		g.first = append(g.first, uint32(len(g.labels)))
+		g.terminal[n] = header&1 == 1
+		// every edge takes at least two bytes
+		if edges := header >> 1; edges > uint64(r.remaining)/2 {
+			return nil, fmt.Errorf("corrupt lexicon: %d edges in %d bytes", edges, r.remaining)
+		}
+		for range header >> 1 {
+			label, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, err
+			}
+			target, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, err
+			}
+			if target >= nodes {
+				return nil, fmt.Errorf("corrupt lexicon: edge to node %d of %d", target, nodes)
+			}
+			g.labels = append(g.labels, rune(label))
+			g.targets = append(g.targets, uint32(target))
+		}
+	}
+	g.first = append(g.first, uint32(len(g.labels)))
+	return &g, nil
+}
@@ -0,0 +1,65 @@
+package lexicon
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+var testWords = []string{"ab", "abs", "bat", "cab", "cabs", "tab"}
+
+func TestSaveLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "test.lex")
+	if err := BuildFromWords(testWords).Save(file); err != nil {
+		t.Fatal(err)
+	}
+	lex, err := Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, word := range testWords {
+		if !lex.Contains(word) {
+			t.Errorf("loaded lexicon does not contain %s", word)
+		}
+	}
+	if lex.Contains("ba") {
+		t.Errorf("loaded lexicon contains ba")
+	}
+	if got := lex.Through("b"); !slices.Contains(got, "cab") {
+		t.Errorf("words through b are %v", got)
+	}
+}
+
+func TestLoadCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "test.lex")
+	if err := BuildFromWords(testWords).Save(file); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a node count far beyond the size of the file
+	huge := binary.AppendUvarint([]byte(magic), 1<<40)
+	// a node with far more edges than the file has bytes
+	edges := binary.AppendUvarint(binary.AppendUvarint([]byte(magic), 1), 1<<40)
+	cases := map[string][]byte{
+		"not a lexicon": []byte("hello"),
+		"truncated":     saved[:len(saved)-3],
+		"huge nodes":    huge,
+		"huge edges":    edges,
+	}
+	for name, contents := range cases {
+		corrupt := filepath.Join(dir, name)
+		if err := os.WriteFile(corrupt, contents, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(corrupt); err == nil {
+			t.Errorf("loaded a %s lexicon file", name)
+		}
+	}
+}
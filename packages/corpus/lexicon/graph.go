@@ -0,0 +1,86 @@
+package lexicon
+
+import (
+	"iter"
+	"sort"
+)
+
+// Node within a Graph
+type Node uint32
+
+// Compiled, immutable acyclic word graph, where every path from the root to a terminal node spells
+// out an entry. Edges are stored contiguously, with the edges out of each node sorted by label
+type Graph struct {
+	// edges out of node i are at indices first[i] to first[i+1]
+	first []uint32
+	// label of each edge
+	labels []rune
+	// node each edge leads to
+	targets []uint32
+	// whether each node ends an entry
+	terminal []bool
+}
+
+// Node that every entry starts from
+func (g *Graph) Root() Node {
+	return 0
+}
+
+// Follows the edge labeled r out of the node, if there is one
+func (g *Graph) Next(n Node, r rune) (Node, bool) {
+	start, end := g.first[n], g.first[n+1]
+	i := start + uint32(sort.Search(int(end-start), func(i int) bool {
+		return g.labels[start+uint32(i)] >= r
+	}))
+	if i < end && g.labels[i] == r {
+		return Node(g.targets[i]), true
+	}
+	return 0, false
+}
+
+// Follows the path spelled out by s from the node, if there is one
+func (g *Graph) Walk(n Node, s []rune) (Node, bool) {
+	for _, r := range s {
+		var ok bool
+		if n, ok = g.Next(n, r); !ok {
+			return 0, false
+		}
+	}
+	return n, true
+}
+
+// Whether a path ending at the node spells out an entry
+func (g *Graph) IsTerminal(n Node) bool {
+	return g.terminal[n]
+}
+
+// The labels of the edges out of the node, and the nodes they lead to, in sorted order
+func (g *Graph) Edges(n Node) iter.Seq2[rune, Node] {
+	return func(yield func(rune, Node) bool) {
+		for i := g.first[n]; i < g.first[n+1]; i++ {
+			if !yield(g.labels[i], Node(g.targets[i])) {
+				return
+			}
+		}
+	}
+}
+
+// Number of nodes in the graph
+func (g *Graph) NodeCount() int {
+	return len(g.terminal)
+}
+
+// Number of edges in the graph
+func (g *Graph) EdgeCount() int {
+	return len(g.labels)
+}
+
+// Calls visit with every suffix which completes an entry from the node, in sorted order
+func (g *Graph) completions(n Node, prefix []rune, visit func([]rune)) {
+	if g.IsTerminal(n) {
+		visit(prefix)
+	}
+	for r, next := range g.Edges(n) {
+		g.completions(next, append(prefix, r), visit)
+	}
+}
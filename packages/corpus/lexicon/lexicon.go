@@ -0,0 +1,166 @@
+package lexicon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/digitaltembo/motli/packages/corpus/sources"
+	"github.com/digitaltembo/motli/packages/corpus/utils"
+)
+
+// Separates the reversed prefix from the suffix of an entry in the GADDAG
+const Separator rune = 0
+
+// Set of words compiled into a DAWG for prefix lookups and a GADDAG for lookups of words
+// through an anchor (e.g. a tile already on the board). Words are stored lower-cased
+type Lexicon struct {
+	// Every word, spelled forwards
+	Dawg *Graph
+	// For every word w and each 0 < i <= len(w), the entry reverse(w[:i]) + Separator + w[i:],
+	// without the Separator when i == len(w)
+	Gaddag *Graph
+}
+
+// Compiles the words of the word source into a Lexicon
+func Build(ws sources.WordSource) *Lexicon {
+	words := []string{}
+	for _, w := range ws.GetWordList() {
+		word := strings.ToLower(w.Word)
+		if word != "" && !strings.ContainsRune(word, Separator) {
+			words = append(words, word)
+		}
+	}
+	return BuildFromWords(words)
+}
+
+// Compiles the words into a Lexicon
+func BuildFromWords(words []string) *Lexicon {
+	// sorting strings sorts by their UTF-8 bytes, which is the same order as by their runes
+	words = slices.Clone(words)
+	slices.Sort(words)
+	words = slices.Compact(words)
+
+	dawg := newBuilder()
+	for _, word := range words {
+		dawg.insert([]rune(word))
+	}
+
+	entries := []string{}
+	for _, word := range words {
+		runes := []rune(word)
+		for i := 1; i <= len(runes); i++ {
+			entry := slices.Clone(runes[:i])
+			slices.Reverse(entry)
+			if i < len(runes) {
+				entry = append(append(entry, Separator), runes[i:]...)
+			}
+			entries = append(entries, string(entry))
+		}
+	}
+	slices.Sort(entries)
+	entries = slices.Compact(entries)
+
+	gaddag := newBuilder()
+	for _, entry := range entries {
+		gaddag.insert([]rune(entry))
+	}
+	return &Lexicon{Dawg: dawg.finish(), Gaddag: gaddag.finish()}
+}
+
+// Loads the compiled lexicon of the word source from the data directory, compiling and saving
+// it first if necessary, or if the word source has changed since it was compiled
+func ForWordSource(ctx context.Context, wordsId sources.WordSourceId) (*Lexicon, error) {
+	file, err := utils.LexiconFile(string(wordsId))
+	if err != nil {
+		return nil, err
+	}
+	var lex *Lexicon
+	built, err := utils.BuildArtifact(ctx, file, map[string]string{"words": string(wordsId)}, func(ctx context.Context) error {
+		ws, err := sources.GetWordSource(ctx, wordsId)
+		if err != nil {
+			return err
+		}
+		lex = Build(ws)
+		fmt.Fprintf(os.Stderr, "Compiled lexicon with %d DAWG nodes and %d GADDAG nodes\n",
+			lex.Dawg.NodeCount(), lex.Gaddag.NodeCount())
+		return lex.Save(file)
+	})
+	if err != nil || built {
+		return lex, err
+	}
+	return Load(file)
+}
+
+// Whether the word is in the lexicon
+func (l *Lexicon) Contains(word string) bool {
+	n, ok := l.Dawg.Walk(l.Dawg.Root(), []rune(strings.ToLower(word)))
+	return ok && l.Dawg.IsTerminal(n)
+}
+
+// Whether any word in the lexicon starts with the prefix
+func (l *Lexicon) HasPrefix(prefix string) bool {
+	_, ok := l.Dawg.Walk(l.Dawg.Root(), []rune(strings.ToLower(prefix)))
+	return ok
+}
+
+// Every word in the lexicon which starts with the prefix, in sorted order
+func (l *Lexicon) WithPrefix(prefix string) []string {
+	prefixRunes := []rune(strings.ToLower(prefix))
+	n, ok := l.Dawg.Walk(l.Dawg.Root(), prefixRunes)
+	if !ok {
+		return nil
+	}
+	words := []string{}
+	l.Dawg.completions(n, prefixRunes, func(word []rune) {
+		words = append(words, string(word))
+	})
+	return words
+}
+
+// Every word in the lexicon which contains the anchor, in sorted order
+func (l *Lexicon) Through(anchor string) []string {
+	anchorRunes := []rune(strings.ToLower(anchor))
+	if len(anchorRunes) == 0 {
+		return nil
+	}
+	reversed := slices.Clone(anchorRunes)
+	slices.Reverse(reversed)
+	n, ok := l.Gaddag.Walk(l.Gaddag.Root(), reversed)
+	if !ok {
+		return nil
+	}
+
+	found := map[string]bool{}
+	// extend the reversed prefix (everything before the anchor, read backwards), and then
+	// after the separator the suffix (everything after the anchor)
+	var extend func(n Node, before []rune, after []rune, separated bool)
+	extend = func(n Node, before []rune, after []rune, separated bool) {
+		if l.Gaddag.IsTerminal(n) {
+			word := slices.Clone(before)
+			slices.Reverse(word)
+			word = append(append(word, anchorRunes...), after...)
+			found[string(word)] = true
+		}
+		for r, next := range l.Gaddag.Edges(n) {
+			switch {
+			case separated:
+				extend(next, before, append(after, r), true)
+			case r == Separator:
+				extend(next, before, after, true)
+			default:
+				extend(next, append(before, r), after, false)
+			}
+		}
+	}
+	extend(n, nil, nil, false)
+
+	words := make([]string, 0, len(found))
+	for word := range found {
+		words = append(words, word)
+	}
+	slices.Sort(words)
+	return words
+}
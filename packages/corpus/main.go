@@ -6,73 +6,135 @@ for tiles and words that is open-source and easily tunable for different games,
 and languages.
 
 Outputs data files in the data directory, including large wiktionary exported files that are
-downloaded when run
+downloaded when run. Outputs which are reused between runs, such as ngram analyses, are recorded
+in a manifest along with what they were built from, and are rebuilt when any of it changes. Every
+command accepts --force to rebuild them regardless
 
 Usage:
 
-	corpus [--download [language]] [--analyze [language [--ngrams [int]] [--tiles [int]]
-
-The flags are:
-
-	--help
-			Print the help text
-
-	--download [language]
-			Download the wikiextract file for the given language, storing the gzipped jsonl files
+	corpus <command> [flags] [arguments]
+	corpus help [command]
+
+The commands are:
+
+	corpus anagram <rack> [--exact] [--max-length int] [--min-length int] [--words string]
+			Finds every word of the word source that can be formed from the rack of tiles, given as tiles
+			separated by commas (e.g. "a,e,qu,?") or one character per tile (e.g. "aeqt?"), where "?" or
+			"_" is a blank. Optionally only exact anagrams, or words within length bounds
+
+	corpus analyze frequencies <language> [--words string]
+			Counts the number of times each word of the word source is used in the language, storing
+			the frequency table as a csv in the data directory. The table is used to populate Word.Freq
+
+	corpus analyze ngrams <source> [--min-count int] [--mode string] [--ngrams string] [--words string] [--workers int]
+			Counts the ngrams of the provided size (e.g. 2) or range of sizes (e.g. 1..5), storing the
+			results as a csv in the data directory. Only ngrams that are observed are counted, and ngrams
+			found in fewer than min-count words are left out. The mode selects which words are counted:
+			"usage" counts every word used in the example text of the language source (the default),
+			"dictionary" counts every word of the word source once, uniformly over the dictionary, and
+			"frequency" counts every word of the --words word source weighted by how often it is used in
+			the language source. The words are read and counted by --workers in parallel,
+			one per CPU by default, and the results are the same for any number of workers
+
+	corpus artifacts [--dot]
+			Shows the artifacts of the data directory which are reused between runs (ngram analyses,
+			frequency tables and lexicons) as a tree from each artifact down to the source files it was built
+			from, along with whether it is up to date and the parameters it was built with. Artifacts are
+			rebuilt automatically when their sources, their parameters or the version of the tool change
+
+	corpus boggle <words> [--boards int] [--dice string] [--seed int] [--size int] [--tiles string]
+			Generates Boggle boards by rolling the classic dice, the dice in a JSON file, or by drawing
+			from a tiles JSON file, and finds every word of the word source on each board, storing the
+			boards, their words, and points as a JSON file in the data directory
+
+	corpus boggle dice <language> [--boards int] [--count int] [--faces int] [--iterations int] [--seed int] [--words string]
+			Designs dice for a square Boggle board, starting from faces in proportion to the letter usage
+			of the language and searching (by simulated annealing) for swaps of faces between dice that
+			maximize the average number of words of the word source found on boards rolled at random,
+			storing the dice as a JSON file in the data directory that can be passed to "boggle --dice"
+
+	corpus download <source>
+			Downloads the wikiextract file for the given language, the wikipedia dumps "wp-en" and
+			"wp-simple-en", or the word lists "csw21" and "nswl2023", storing them in the data directory.
+			See "corpus sources list" for every file that can be downloaded
+
+	corpus lexicon <words>
+			Compiles the word source into a minimized DAWG and GADDAG for fast prefix and anchor lookups
+			by game solvers, storing them as a binary file in the data directory
+
+	corpus optimize <tiles file> [--iterations int] [--min string] [--rack int] [--racks int] [--seed int] [--words string]
+			Searches (by simulated annealing) for a distribution with the same number of tiles as the
+			tiles JSON file that maximizes the average number of words formable from random racks, keeping
+			at least one of each tile or the comma-separated minimums (e.g. "q=1,z=1"), storing the best
+			distribution and the history of the search as a JSON file in the data directory
+
+	corpus playability <tiles file> [--blanks int] [--rack int] [--racks int] [--seed int] [--words string]
+			Draws racks of tiles at random from the tiles JSON file, plus an optional number of blanks,
+			and counts how many words of the word source each rack can form, storing the mean/median/
+			percentiles and fraction of dead racks as a JSON file in the data directory
+
+	corpus scores <language> [--max-score int] [--model string] [--words string]
+			Runs analysis of ngram size of 1 and computes a point value from 1 to max-score for each
+			tile, storing the results as a JSON file in the data directory. Models are "inverse" (rank of
+			rarity), "entropy" (information content) and "valett" (rarity, usefulness in short words and
+			ease of placement, which requires a word source)
+
+	corpus self-play <tiles file> [--blanks int] [--games int] [--scores string] [--seed int] [--words string]
+			Plays games of Scrabble on the standard board between two players drawing from the tiles
+			JSON file plus an optional number of blanks, who always play the highest scoring move of the
+			word source with the letter scores of the scores JSON file, storing the mean and variance of
+			their scores, the bingo rate, and the fraction of each letter left stuck on a rack at the end
+			of a game as a JSON file in the data directory
+
+	corpus sources list
+			Lists the ids of every language source, word source and file that can be downloaded
+
+	corpus spelling-bee <language> [--max-answers int] [--max-freq int] [--min-answers int] [--min-freq int] [--words string]
+			Generates Spelling Bee puzzles from every set of seven letters of a word of the word source,
+			with each choice of center letter, whose answers are the words of at least four letters used
+			in the language. Puzzles are scored (1 point for four letters, a point per letter otherwise,
+			and 7 more for a pangram) and kept if they have between min-answers and max-answers answers,
+			and if the median usage of their answers is between min-freq and max-freq, storing them as a
+			JSON file in the data directory
+
+	corpus tiles <language> [--count int] [--multi string] [--multi-top int]
+			Runs analysis of ngram size of 1 and creates a set of tiles of the provided size whose
+			frequency corresponds to the frequency of the ngrams in that language's corpus, storing the
+			results as a JSON file in the data directory. Multi-letter tiles can be included, either the
+			provided comma-separated list (e.g. "qu,th,ing") or the top most useful digraphs/trigraphs/
+			tetragraphs, discounting the letters they consume from the single-letter tiles
+
+	corpus word-search <language> [--count int] [--directions string] [--height int] [--seed int] [--theme string] [--width int] [--words string]
+			Generates a word search hiding either the comma-separated words of the theme or random words
+			of the word source, crossing where their letters agree, along the directions: "easy" (right
+			and down), "medium" (also diagonally), "hard" (also backwards) or a list like
+			"right,down,down-right". The rest of the grid is filled following a set of tiles of the
+			language, redrawn wherever extra words of the word source appear, storing the puzzle as a JSON
+			file in the data directory
+
+	corpus wordle <language> [--answers int] [--length int] [--seed int] [--start string] [--words string]
+			Generates Wordle lists of words from the word source: every reasonable lower-case word as an
+			allowed guess, and as answers the words used in the language, without plurals, names or
+			abbreviations, ranked by usage into easy, medium and hard thirds. Also schedules one answer
+			per day in a seeded shuffle from the start date, storing the lists and schedule as a JSON file
 			in the data directory
 
-	--analyze [language]
-			Run analysis on the language, defaulting to an ngram analysis of size 1
-
-	--analyse [language] --ngrams [int]
-			Run ngram analysis of the provided size, storing thee results as a csv in the data directory
-
-	--analyse [language] --tiles [int]
-			Run analysis of ngram size of 1 and create a set of tiles of the provided size whose
-			frequency corresponds to the frequency of the ngrams in that language's corpus, storing the
-			results as a JSON file in the data directory
+	corpus wordle solve <words> [--answers string] [--length int] [--openers int]
+			Plays every Wordle answer (from a word list or "wordle" JSON file, or by default every word
+			of the length) with a solver always making the guess from the word source that gives the most
+			information, storing the guesses each answer took, the distribution of guesses, and the best
+			opening guesses as a JSON file in the data directory
 */
 package main
 
 import (
-	"fmt"
+	"os"
 
-	"github.com/digitaltembo/motli/packages/corpus/processes"
-	"github.com/digitaltembo/motli/packages/corpus/sources"
+	// processes register the commands of the corpus tool
+	_ "github.com/digitaltembo/motli/packages/corpus/processes"
 	"github.com/digitaltembo/motli/packages/corpus/utils"
 )
 
 func main() {
-	args := utils.ParseArgs()
-
-	if args.Download != nil {
-		filename, err := sources.DownloadWikiExtract(sources.WikiExtractLanguage(args.Download.Language))
-		if err != nil {
-			fmt.Printf("Failed to download %s: %s\n", args.Download.Language, err.Error())
-		} else {
-			fmt.Printf("File downloaded at %s\n", filename)
-		}
-		return
-	}
-
-	if args.Analyze != nil {
-		if args.Analyze.Tiles > 0 {
-			_, err := processes.TileSet(
-				sources.LanguageSourceId(args.Analyze.Language),
-				args.Analyze.Tiles)
-
-			if err != nil {
-				fmt.Printf("Failed to get tiles %s: %s\n", args.Analyze.Language, err.Error())
-			}
-		} else {
-			_, err := processes.AnalyzeNgrams(sources.LanguageSourceId(args.Analyze.Language), args.Analyze.Ngrams)
-
-			if err != nil {
-				fmt.Printf("Failed to analyze %s: %s\n", args.Analyze.Language, err.Error())
-			}
-		}
-		return
-	}
-
-	fmt.Println("Didn't do anything")
+	os.Exit(utils.RunCommand(os.Args[1:]))
 }
@@ -0,0 +1,51 @@
+package processes
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/digitaltembo/motli/packages/corpus/anagram"
+	"github.com/digitaltembo/motli/packages/corpus/sources"
+	"github.com/digitaltembo/motli/packages/corpus/utils"
+)
+
+func init() {
+	utils.RegisterCommand(&utils.Command{
+		Name:    "anagram",
+		Args:    []string{"rack"},
+		Summary: "Find the words that can be formed from a rack of tiles",
+		Description: `Finds every word of the word source that can be formed from the rack of tiles, given as tiles
+separated by commas (e.g. "a,e,qu,?") or one character per tile (e.g. "aeqt?"), where "?" or
+"_" is a blank. Optionally only exact anagrams, or words within length bounds`,
+		Setup: func(flags *flag.FlagSet) func(ctx context.Context, args []string) error {
+			words := flags.String("words", "", "Word source of words which can be formed (required)")
+			exact := flags.Bool("exact", false, "Only find words using every tile of the rack")
+			minLength := flags.Int("min-length", 0, "Only find words with at least this many letters")
+			maxLength := flags.Int("max-length", 0, "Only find words with at most this many letters")
+			return func(ctx context.Context, args []string) error {
+				if *words == "" {
+					return fmt.Errorf("the --words flag is required")
+				}
+				ws, err := sources.GetWordSource(ctx, sources.WordSourceId(*words))
+				if err != nil {
+					return err
+				}
+				index := anagram.NewIndex(ws)
+				start := time.Now()
+				found := index.Solve(anagram.ParseRack(args[0]), anagram.Options{
+					Exact:     *exact,
+					MinLength: *minLength,
+					MaxLength: *maxLength,
+				})
+				fmt.Fprintf(os.Stderr, "Found %d words in %s\n", len(found), time.Since(start))
+				for _, word := range found {
+					fmt.Println(word)
+				}
+				return nil
+			}
+		},
+	})
+}
@@ -1,13 +1,21 @@
 package processes
 
 import (
+	"cmp"
+	"context"
 	"encoding/csv"
+	"flag"
 	"fmt"
-	"maps"
+	"io"
+	"iter"
 	"os"
+	"runtime"
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
 
 	"github.com/digitaltembo/motli/packages/corpus/sources"
 	"github.com/digitaltembo/motli/packages/corpus/utils"
@@ -30,6 +38,14 @@ func (c Counts) toString() string {
 	return fmt.Sprintf("%d,%d,%d,%d", c.Count, c.Multi, c.Prefix, c.Suffix)
 }
 
+// Adds the counts of other, e.g. to merge counts over separate sets of words
+func (c *Counts) add(other Counts) {
+	c.Count += other.Count
+	c.Multi += other.Multi
+	c.Prefix += other.Prefix
+	c.Suffix += other.Suffix
+}
+
 // Parse count representation from a portion of a line of a CSV
 func (c *Counts) readAtOffset(records []string, offset int) {
 	c.Count, _ = strconv.Atoi(records[offset])
@@ -57,133 +73,392 @@ func (a *Analysis) read(records []string) {
 	a.CorpusCounts.readAtOffset(records, 1)
 }
 
-// Analyze the frequency of ngrams in the corpus of example sentences
-// for all words of language in the wiktionary, and save the output as a csv
-// TODO(): Allow for other corpora? The dictionary examples may be biased
-func AnalyzeNgrams(languageId sources.LanguageSourceId, n int) ([]*Analysis, error) {
-	outputFile, err := utils.NgramFile(string(languageId), n)
+// Which distribution of words an ngram analysis counts ngrams over
+type AnalysisMode string
+
+const (
+	// Every word of the word source is counted once, i.e. uniform over the dictionary
+	AnalysisMode_Dictionary = "dictionary"
+	// Every word of the language source is counted each time it is used in the example text
+	AnalysisMode_Usage = "usage"
+	// Every word of the word source is counted as many times as it is used in the
+	// language source, using Word.Freq
+	AnalysisMode_Frequency = "frequency"
+)
+
+// Analyze the frequency of ngrams over the words selected by the analysis mode - the
+// words of the word source for AnalysisMode_Dictionary, the words used in the language
+// source for AnalysisMode_Usage, and both for AnalysisMode_Frequency - and save the output as a csv,
+// which is reused until its sources change (see utils.BuildArtifact).
+// Ngrams of every size in the range are counted, and ngrams seen in fewer than minCount words are left out.
+// The words are read and counted by the number of workers in parallel (or one per CPU if it is less than 1),
+// and the output is the same for any number of workers
+func AnalyzeNgrams(ctx context.Context, mode AnalysisMode, languageId sources.LanguageSourceId, wordsId sources.WordSourceId, ngrams NgramRange, minCount int, workers int) ([]*Analysis, error) {
+	name, err := analysisName(mode, languageId, wordsId)
+	if err != nil {
+		return nil, err
+	}
+	if minCount > 0 {
+		name = fmt.Sprintf("%s-min%d", name, minCount)
+	}
+	outputFile, err := utils.NgramFile(name, ngrams.String())
 	if err != nil {
 		return nil, err
 	}
 
-	if !utils.FileExists(outputFile) {
-		language, err := sources.GetLanguageSource(languageId)
+	params := map[string]string{
+		"mode":     string(mode),
+		"language": string(languageId),
+		"words":    string(wordsId),
+		"ngrams":   ngrams.String(),
+		"minCount": strconv.Itoa(minCount),
+	}
+	var analysis []*Analysis
+	built, err := utils.BuildArtifact(ctx, outputFile, params, func(ctx context.Context) error {
+		if workers < 1 {
+			workers = runtime.NumCPU()
+		}
+		// stops reading the words if a worker fails
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		alphabet, shards, err := analysisWords(ctx, mode, languageId, wordsId, workers)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		// a partial analysis is never saved, as it would be mistaken for a complete one
+		analysis, err = analyze(shards, alphabet, ngrams, minCount)
+		if err != nil {
+			return err
 		}
 
 		output, err := os.Create(outputFile)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		defer output.Close()
+		return writeAnalysis(output, analysis)
+	})
+	if err != nil || built {
+		return analysis, err
+	}
 
-		ngrams, err := ngrams(language, n)
-		if err != nil {
-			return nil, err
+	fmt.Fprintf(os.Stderr, "Already analyzed!\n")
+	f, err := os.Open(outputFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	csvReader := csv.NewReader(f)
+	records, err := csvReader.ReadAll()
+
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("ngram analysis %s is empty", outputFile)
+	}
+	analysis = []*Analysis{}
+	for _, record := range records[1:] {
+		if len(record) > 4 {
+			newAnalysis := Analysis{}
+			newAnalysis.read(record)
+			analysis = append(analysis, &newAnalysis)
 		}
-		return analyze(output, language, ngrams)
-	} else {
-		fmt.Fprintf(os.Stderr, "Already analyzed!\n")
-		f, err := os.Open(outputFile)
-		if err != nil {
-			return nil, err
+	}
+	return analysis, nil
+}
+
+// Writes the analysis as a CSV with a line for each ngram
+func writeAnalysis(w io.Writer, analysis []*Analysis) error {
+	if _, err := fmt.Fprintln(w, "string,corpusCount,corpusMulti,corpusPrefix,corpusSuffix"); err != nil {
+		return err
+	}
+	for _, a := range analysis {
+		if _, err := fmt.Fprintln(w, a.toString()); err != nil {
+			return err
 		}
-		defer f.Close()
+	}
+	return nil
+}
 
-		csvReader := csv.NewReader(f)
-		records, err := csvReader.ReadAll()
+// Name identifying the sources and mode of an analysis, used for naming its output files
+func analysisName(mode AnalysisMode, languageId sources.LanguageSourceId, wordsId sources.WordSourceId) (string, error) {
+	switch mode {
+	case AnalysisMode_Dictionary:
+		if wordsId == "" {
+			return "", fmt.Errorf("dictionary analysis requires a word source")
+		}
+		return fmt.Sprintf("%s-%s", wordsId, mode), nil
+	case AnalysisMode_Usage:
+		if languageId == "" {
+			return "", fmt.Errorf("usage analysis requires a language source")
+		}
+		return fmt.Sprintf("%s-%s", languageId, mode), nil
+	case AnalysisMode_Frequency:
+		if languageId == "" || wordsId == "" {
+			return "", fmt.Errorf("frequency analysis requires a language source and a word source")
+		}
+		return fmt.Sprintf("%s-%s-%s", languageId, wordsId, mode), nil
+	default:
+		return "", fmt.Errorf("unsupported analysis mode: %s", mode)
+	}
+}
 
+// A word to analyze, along with how many times to count it
+type weightedWord struct {
+	word   string
+	weight int
+}
+
+// Number of words handed to a worker at a time when sharing out a sequence of words
+const analysisBatchSize = 1024
+
+// The alphabet and the sequence of words (along with how many times to count each word)
+// to analyze for the analysis mode, split into a share for each of the workers
+func analysisWords(ctx context.Context, mode AnalysisMode, languageId sources.LanguageSourceId, wordsId sources.WordSourceId, workers int) (string, []iter.Seq2[weightedWord, error], error) {
+	share := func(words iter.Seq2[weightedWord, error]) []iter.Seq2[weightedWord, error] {
+		if workers == 1 {
+			return []iter.Seq2[weightedWord, error]{words}
+		}
+		return sources.Shard(ctx, words, workers, analysisBatchSize)
+	}
+	switch mode {
+	case AnalysisMode_Dictionary:
+		ws, err := sources.GetWordSource(ctx, wordsId)
 		if err != nil {
-			return nil, err
+			return "", nil, err
 		}
-		analysis := []*Analysis{}
-		for _, record := range records[1:] {
-			if len(record) > 4 {
-				newAnalysis := Analysis{}
-				newAnalysis.read(record)
-				analysis = append(analysis, &newAnalysis)
+		return ws.Alphabet(), share(func(yield func(weightedWord, error) bool) {
+			for _, word := range ws.GetWordList() {
+				if !yield(weightedWord{word.Word, 1}, nil) {
+					return
+				}
 			}
+		}), nil
+
+	case AnalysisMode_Usage:
+		language, err := sources.GetLanguageSource(ctx, languageId)
+		if err != nil {
+			return "", nil, err
+		}
+		// the language source shares out its decoding and tokenizing as well, where it can
+		words := []iter.Seq2[string, error]{language.Read(ctx)}
+		if workers > 1 {
+			words = sources.ReadShards(ctx, language, workers)
+		}
+		shards := []iter.Seq2[weightedWord, error]{}
+		for _, shard := range words {
+			shards = append(shards, sources.Map(shard, func(word string) weightedWord {
+				return weightedWord{word, 1}
+			}))
+		}
+		return language.Alphabet(), shards, nil
+
+	case AnalysisMode_Frequency:
+		ws, err := FrequencyWordSource(ctx, languageId, wordsId)
+		if err != nil {
+			return "", nil, err
 		}
-		return analysis, nil
+		return ws.Alphabet(), share(func(yield func(weightedWord, error) bool) {
+			for _, word := range ws.GetWordList() {
+				if word.Freq > 0 && !yield(weightedWord{word.Word, word.Freq}, nil) {
+					return
+				}
+			}
+		}), nil
+
+	default:
+		return "", nil, fmt.Errorf("unsupported analysis mode: %s", mode)
 	}
 }
 
-// Generate an analysis of the frequency of occurrences of words as substrings in the
-// wiktionary example sentences of the provided language, and save it as a csv
-func analyze(out *os.File, languageSource sources.LanguageSource, symbols []string) ([]*Analysis, error) {
-	symbolMap := map[string]*Analysis{}
-	for _, symbol := range symbols {
-		symbolMap[symbol] = &Analysis{Symbol: symbol}
-	}
+// Inclusive range of ngram sizes to analyze, e.g. {1, 1} for letters or {2, 3} for digrams and trigrams
+type NgramRange struct {
+	Min int
+	Max int
+}
 
-	wordCh, err := languageSource.Read()
+// Parses either a single size ("2") or an inclusive range of sizes ("1..5")
+func ParseNgramRange(s string) (NgramRange, error) {
+	minStr, maxStr, isRange := strings.Cut(s, "..")
+	if !isRange {
+		maxStr = minStr
+	}
+	min, err := strconv.Atoi(minStr)
 	if err != nil {
-		return nil, err
+		return NgramRange{}, fmt.Errorf("invalid ngram size %q", s)
+	}
+	max, err := strconv.Atoi(maxStr)
+	if err != nil {
+		return NgramRange{}, fmt.Errorf("invalid ngram size %q", s)
+	}
+	if min < 1 || max < min {
+		return NgramRange{}, fmt.Errorf("invalid ngram range %q", s)
 	}
+	return NgramRange{Min: min, Max: max}, nil
+}
 
-	analyzed := 0
-	for {
-		word := <-wordCh
+// Representation used in file names, e.g. "1" or "1-5"
+func (r NgramRange) String() string {
+	if r.Min == r.Max {
+		return strconv.Itoa(r.Min)
+	}
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
 
-		if word == nil {
-			break
-		} else {
-			analyzed++
-			if analyzed%100000 == 0 {
-				fmt.Fprintf(os.Stderr, "Analyzed %d words (latest: %s)\n", analyzed, *word)
-			}
-			for _, symbol := range symbols {
-				updateCount(&symbolMap[symbol].CorpusCounts, symbol, *word)
+// Generate an analysis of the frequency of occurrences of the ngrams in the range as substrings
+// of the words, counting each word weight times. Only ngrams made up of
+// letters of the alphabet which are actually observed are tracked (along with every letter of the
+// alphabet when analyzing 1-grams), and ngrams seen in fewer than minCount words are pruned. Each
+// shard of the words is counted by its own worker, and the counts are merged once they are all done.
+// Fails with the first error of the words
+func analyze(shards []iter.Seq2[weightedWord, error], alphabet string, ngrams NgramRange, minCount int) ([]*Analysis, error) {
+	inAlphabet := map[rune]bool{}
+	for _, r := range alphabet {
+		inAlphabet[r] = true
+	}
+
+	counts := make([]map[string]*Analysis, len(shards))
+	errs := make([]error, len(shards))
+	var failed atomic.Bool
+	var analyzed atomic.Int64
+	var wg sync.WaitGroup
+	for i, shard := range shards {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			symbolMap := map[string]*Analysis{}
+			counts[i] = symbolMap
+			for word, err := range shard {
+				if err != nil {
+					errs[i] = err
+					failed.Store(true)
+					return
+				}
+				if failed.Load() {
+					return
+				}
+				if count := analyzed.Add(1); count%100000 == 0 {
+					fmt.Fprintf(os.Stderr, "Analyzed %d words (latest: %s)\n", count, word.word)
+				}
+				countNgrams(symbolMap, inAlphabet, ngrams, word.word, word.weight)
 			}
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
 		}
 	}
-	fmt.Fprintln(out, "string,corpusCount,corpusMulti,corpusPrefix,corpusSuffix")
 
-	for _, symbol := range symbols {
-		fmt.Fprintln(out, symbolMap[symbol].toString())
+	symbolMap := map[string]*Analysis{}
+	if ngrams.Min == 1 {
+		for r := range inAlphabet {
+			symbolMap[string(r)] = &Analysis{Symbol: string(r)}
+		}
 	}
-	return slices.Collect(maps.Values(symbolMap)), nil
-}
-
-// Return the list of ngrams up to the count
-// ie ngrams('en', 1) => ["a", "b", ...]
-// ngrams('en', 2) => ["aa", "ab", ...]
-func ngrams(languageSource sources.LanguageSource, n int) ([]string, error) {
-	letters := languageSource.Alphabet()
-	runes := []rune(letters)
-	ngrams := []string{}
-	for _, r := range runes {
-		ngrams = append(ngrams, string(r))
+	for _, workerCounts := range counts {
+		for symbol, a := range workerCounts {
+			if merged, ok := symbolMap[symbol]; ok {
+				merged.CorpusCounts.add(a.CorpusCounts)
+			} else {
+				symbolMap[symbol] = a
+			}
+		}
 	}
 
-	for i := 1; i < n; i++ {
-		newNgrams := []string{}
-		// newNgrams := make([]string, len(ngrams))
-		// copy(newNgrams, ngrams)
-		for _, ngram := range ngrams {
-			for _, r := range runes {
-				newNgrams = append(newNgrams, ngram+string(r))
-			}
+	analysis := []*Analysis{}
+	for _, a := range symbolMap {
+		if a.CorpusCounts.Count >= minCount {
+			analysis = append(analysis, a)
 		}
-		ngrams = newNgrams
 	}
-	return ngrams, nil
+	sortAnalysis(analysis)
+	return analysis, nil
 }
-func updateCount(counts *Counts, symbol string, inWord string) {
-	inWord = strings.ToLower(inWord)
-	if strings.HasPrefix(inWord, symbol) {
-		counts.Prefix++
-	}
-	if strings.HasSuffix(inWord, symbol) {
-		counts.Suffix++
+
+// Slides a window of each size in the range across the word, updating the counts of
+// each ngram of letters in the alphabet that it finds
+func countNgrams(symbolMap map[string]*Analysis, inAlphabet map[rune]bool, ngrams NgramRange, word string, weight int) {
+	runes := []rune(strings.ToLower(word))
+	// occurrences of each ngram in this word
+	seen := map[string]int{}
+	for n := ngrams.Min; n <= ngrams.Max; n++ {
+	window:
+		for i := 0; i+n <= len(runes); i++ {
+			for _, r := range runes[i : i+n] {
+				if !inAlphabet[r] {
+					continue window
+				}
+			}
+			symbol := string(runes[i : i+n])
+			a, ok := symbolMap[symbol]
+			if !ok {
+				a = &Analysis{Symbol: symbol}
+				symbolMap[symbol] = a
+			}
+			if i == 0 {
+				a.CorpusCounts.Prefix += weight
+			}
+			if i+n == len(runes) {
+				a.CorpusCounts.Suffix += weight
+			}
+			seen[symbol]++
+		}
 	}
-	i := strings.Index(inWord, symbol)
-	li := strings.LastIndex(inWord, symbol)
-	if i >= 0 {
-		counts.Count++
-		if i != li {
-			counts.Multi++
+	for symbol, occurrences := range seen {
+		counts := &symbolMap[symbol].CorpusCounts
+		counts.Count += weight
+		if occurrences > 1 {
+			counts.Multi += weight
 		}
 	}
 }
+
+// Sorts the analysis by the length of the ngram, and then alphabetically
+func sortAnalysis(analysis []*Analysis) {
+	slices.SortFunc(analysis, func(a, b *Analysis) int {
+		if c := cmp.Compare(utf8.RuneCountInString(a.Symbol), utf8.RuneCountInString(b.Symbol)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Symbol, b.Symbol)
+	})
+}
+
+func init() {
+	utils.RegisterCommand(&utils.Command{
+		Name:    "analyze ngrams",
+		Args:    []string{"source"},
+		Summary: "Count the ngrams of a language or word source",
+		Description: `Counts the ngrams of the provided size (e.g. 2) or range of sizes (e.g. 1..5), storing the
+results as a csv in the data directory. Only ngrams that are observed are counted, and ngrams
+found in fewer than min-count words are left out. The mode selects which words are counted:
+"usage" counts every word used in the example text of the language source (the default),
+"dictionary" counts every word of the word source once, uniformly over the dictionary, and
+"frequency" counts every word of the --words word source weighted by how often it is used in
+the language source. The words are read and counted by --workers in parallel,
+one per CPU by default, and the results are the same for any number of workers`,
+		Setup: func(flags *flag.FlagSet) func(ctx context.Context, args []string) error {
+			mode := flags.String("mode", AnalysisMode_Usage, "Count ngrams over the words of the dictionary, language usage or frequency-weighted dictionary")
+			words := flags.String("words", "", "Word source of the frequency mode")
+			ngrams := flags.String("ngrams", "1", "Size of ngrams to count, or range of sizes like 1..5")
+			minCount := flags.Int("min-count", 0, "Leave ngrams occurring in fewer words than this out of the analysis")
+			workers := flags.Int("workers", runtime.NumCPU(), "Number of workers reading and counting the words in parallel")
+			return func(ctx context.Context, args []string) error {
+				ngramRange, err := ParseNgramRange(*ngrams)
+				if err != nil {
+					return err
+				}
+				languageId, wordsId := sources.LanguageSourceId(args[0]), sources.WordSourceId(*words)
+				if *mode == AnalysisMode_Dictionary {
+					// dictionary analysis is over a word source alone
+					languageId, wordsId = "", sources.WordSourceId(args[0])
+				}
+				_, err = AnalyzeNgrams(ctx, AnalysisMode(*mode), languageId, wordsId, ngramRange, *minCount, *workers)
+				return err
+			}
+		},
+	})
+}
@@ -0,0 +1,172 @@
+package processes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"iter"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/digitaltembo/motli/packages/corpus/sources"
+)
+
+const analyzeTestAlphabet = "abcdefghijklmnopqrstuvwxyz"
+
+const analyzeTestText = `The quick brown fox jumps over the lazy dog and the dog sleeps while
+the fox runs across the meadow toward the barrel of apples near the old mill where
+the miller keeps his bees and his sheep eat the grass beside the Mississippi's banks`
+
+// Words weighted the way each analysis mode weights them
+func analyzeTestWords() map[AnalysisMode][]weightedWord {
+	usage := []weightedWord{}
+	dictionary := []weightedWord{}
+	frequency := []weightedWord{}
+	frequencies := map[string]int{}
+	for _, word := range strings.Fields(analyzeTestText) {
+		usage = append(usage, weightedWord{word, 1})
+		if frequencies[word] == 0 {
+			dictionary = append(dictionary, weightedWord{word, 1})
+		}
+		frequencies[word]++
+	}
+	for _, w := range dictionary {
+		frequency = append(frequency, weightedWord{w.word, frequencies[w.word]})
+	}
+	return map[AnalysisMode][]weightedWord{
+		AnalysisMode_Dictionary: dictionary,
+		AnalysisMode_Usage:      usage,
+		AnalysisMode_Frequency:  frequency,
+	}
+}
+
+// Sequence of the words, followed by the error if there is one
+func weightedWords(words []weightedWord, err error) iter.Seq2[weightedWord, error] {
+	return func(yield func(weightedWord, error) bool) {
+		for _, word := range words {
+			if !yield(word, nil) {
+				return
+			}
+		}
+		if err != nil {
+			yield(weightedWord{}, err)
+		}
+	}
+}
+
+// Endless sequence of the word, until it is stopped
+func repeatedWord(word string) iter.Seq2[weightedWord, error] {
+	return func(yield func(weightedWord, error) bool) {
+		for yield(weightedWord{word, 1}, nil) {
+		}
+	}
+}
+
+// CSV of the analysis of the words, shared out between the number of workers
+func analysisCsv(t *testing.T, words []weightedWord, workers int, ngrams NgramRange, minCount int) []byte {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	shards := []iter.Seq2[weightedWord, error]{weightedWords(words, nil)}
+	if workers > 1 {
+		// small batches, so that every worker gets some of the words
+		shards = sources.Shard(ctx, weightedWords(words, nil), workers, 3)
+	}
+	analysis, err := analyze(shards, analyzeTestAlphabet, ngrams, minCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := writeAnalysis(&out, analysis); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func TestAnalyzeSameForAnyWorkers(t *testing.T) {
+	ranges := []NgramRange{{Min: 1, Max: 1}, {Min: 1, Max: 3}, {Min: 2, Max: 4}}
+	for mode, words := range analyzeTestWords() {
+		for _, ngrams := range ranges {
+			for _, minCount := range []int{0, 2} {
+				want := analysisCsv(t, words, 1, ngrams, minCount)
+				for _, workers := range []int{2, 4, 7} {
+					got := analysisCsv(t, words, workers, ngrams, minCount)
+					if !bytes.Equal(got, want) {
+						t.Errorf("%s analysis of %s ngrams (min count %d) with %d workers differs from 1 worker:\n%s\nwant:\n%s",
+							mode, ngrams, minCount, workers, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestAnalyzeWeights(t *testing.T) {
+	words := []weightedWord{{"bee", 3}, {"Eel", 2}, {"can't", 1}}
+	analysis, err := analyze([]iter.Seq2[weightedWord, error]{weightedWords(words, nil)}, analyzeTestAlphabet, NgramRange{Min: 1, Max: 2}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]Counts{}
+	for _, a := range analysis {
+		counts[a.Symbol] = a.CorpusCounts
+	}
+	want := map[string]Counts{
+		"e":  {Count: 5, Multi: 5, Prefix: 2, Suffix: 3},
+		"ee": {Count: 5, Prefix: 2, Suffix: 3},
+		"b":  {Count: 3, Prefix: 3},
+		"be": {Count: 3, Prefix: 3},
+		"l":  {Count: 2, Suffix: 2},
+		"el": {Count: 2, Suffix: 2},
+		"c":  {Count: 1, Prefix: 1},
+		"ca": {Count: 1, Prefix: 1},
+		"t":  {Count: 1, Suffix: 1},
+	}
+	for symbol, c := range want {
+		if counts[symbol] != c {
+			t.Errorf("counts of %q are %+v, want %+v", symbol, counts[symbol], c)
+		}
+	}
+	if _, ok := counts["n'"]; ok {
+		t.Errorf("counted an ngram outside of the alphabet")
+	}
+	if _, ok := counts["z"]; ok {
+		t.Errorf("kept a letter seen in fewer than the minimum count of words")
+	}
+}
+
+func TestAnalyzeShardedReadError(t *testing.T) {
+	readErr := errors.New("read failed")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	words := analyzeTestWords()[AnalysisMode_Usage]
+	shards := sources.Shard(ctx, weightedWords(words, readErr), 4, 3)
+	if _, err := analyze(shards, analyzeTestAlphabet, NgramRange{Min: 1, Max: 2}, 0); !errors.Is(err, readErr) {
+		t.Errorf("got error %v, want %v", err, readErr)
+	}
+}
+
+func TestAnalyzeErrorStopsEveryWorker(t *testing.T) {
+	readErr := errors.New("read failed")
+	words := analyzeTestWords()[AnalysisMode_Usage]
+	shards := []iter.Seq2[weightedWord, error]{
+		repeatedWord("apple"),
+		weightedWords(words[:5], readErr),
+		repeatedWord("banana"),
+		repeatedWord("cherry"),
+	}
+	done := make(chan error)
+	go func() {
+		_, err := analyze(shards, analyzeTestAlphabet, NgramRange{Min: 1, Max: 1}, 0)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, readErr) {
+			t.Errorf("got error %v, want %v", err, readErr)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("workers kept reading after an error")
+	}
+}
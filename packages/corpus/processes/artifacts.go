@@ -0,0 +1,92 @@
+package processes
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/digitaltembo/motli/packages/corpus/utils"
+)
+
+// Prints the artifacts of the manifest as a tree, from the artifacts nothing else is built from down
+// to the source files, with whether each artifact is up to date and the parameters it was built with
+func printArtifacts(w io.Writer, manifest *utils.Manifest) {
+	var print func(file string, depth int)
+	print = func(file string, depth int) {
+		indent := strings.Repeat("    ", depth)
+		artifact, ok := manifest.Artifacts[file]
+		if !ok {
+			fmt.Fprintf(w, "%s%s\n", indent, file)
+			return
+		}
+		status := "up to date"
+		if reason := manifest.Staleness(file); reason != "" {
+			status = "stale: " + reason
+		}
+		fmt.Fprintf(w, "%s%s [%s]\n", indent, file, status)
+		params := []string{}
+		for _, key := range slices.Sorted(maps.Keys(artifact.Params)) {
+			if artifact.Params[key] != "" {
+				params = append(params, fmt.Sprintf("%s=%s", key, artifact.Params[key]))
+			}
+		}
+		fmt.Fprintf(w, "%s  built %s by version %s with %s\n",
+			indent, artifact.Built.Format("2006-01-02 15:04"), artifact.ToolVersion, strings.Join(params, " "))
+		for _, input := range slices.Sorted(maps.Keys(artifact.Inputs)) {
+			print(input, depth+1)
+		}
+	}
+	for _, root := range manifest.Roots() {
+		print(root, 0)
+	}
+}
+
+// Prints the artifacts of the manifest as a Graphviz digraph, with an edge from each input to
+// the artifacts built from it
+func printArtifactsDot(w io.Writer, manifest *utils.Manifest) {
+	fmt.Fprintln(w, "digraph artifacts {")
+	for _, file := range slices.Sorted(maps.Keys(manifest.Artifacts)) {
+		color := "black"
+		if manifest.Staleness(file) != "" {
+			color = "red"
+		}
+		fmt.Fprintf(w, "\t%q [shape=box, color=%s];\n", file, color)
+		for _, input := range slices.Sorted(maps.Keys(manifest.Artifacts[file].Inputs)) {
+			fmt.Fprintf(w, "\t%q -> %q;\n", input, file)
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func init() {
+	utils.RegisterCommand(&utils.Command{
+		Name:    "artifacts",
+		Summary: "Show the cached artifacts and what they were built from",
+		Description: `Shows the artifacts of the data directory which are reused between runs (ngram analyses,
+frequency tables and lexicons) as a tree from each artifact down to the source files it was built
+from, along with whether it is up to date and the parameters it was built with. Artifacts are
+rebuilt automatically when their sources, their parameters or the version of the tool change`,
+		Setup: func(flags *flag.FlagSet) func(ctx context.Context, args []string) error {
+			dot := flags.Bool("dot", false, "Print the graph of artifacts in the Graphviz dot format")
+			return func(ctx context.Context, args []string) error {
+				manifest, err := utils.ReadManifest()
+				if err != nil {
+					return err
+				}
+				if *dot {
+					printArtifactsDot(os.Stdout, manifest)
+				} else if len(manifest.Artifacts) == 0 {
+					fmt.Println("No artifacts have been built")
+				} else {
+					printArtifacts(os.Stdout, manifest)
+				}
+				return nil
+			}
+		},
+	})
+}
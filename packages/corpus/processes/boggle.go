@@ -0,0 +1,127 @@
+package processes
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/digitaltembo/motli/packages/corpus/boggle"
+	"github.com/digitaltembo/motli/packages/corpus/lexicon"
+	"github.com/digitaltembo/motli/packages/corpus/sources"
+	"github.com/digitaltembo/motli/packages/corpus/utils"
+)
+
+// Summary of the words found on randomly generated Boggle boards
+type BoggleReport struct {
+	// Average number of words found on a board
+	MeanWords float64 `json:"meanWords"`
+	// Average total points of the words found on a board
+	MeanPoints float64 `json:"meanPoints"`
+	// Fraction of boards on which no words can be found
+	DeadBoards float64 `json:"deadBoards"`
+	// Every board generated, along with its solution
+	Boards []BoggleBoard `json:"boards"`
+}
+
+// A generated Boggle board and its solution
+type BoggleBoard struct {
+	Rows [][]string `json:"rows"`
+	boggle.Solution
+}
+
+// Generates size by size Boggle boards, either by rolling the dice in diceFile, by drawing from the
+// tiles in tilesFile, or if neither is provided by rolling the classic Boggle dice, and solves each
+// against the lexicon of the word source, saving the boards, their words and points as JSON
+func SimulateBoggle(ctx context.Context, wordsId sources.WordSourceId, diceFile string, tilesFile string, boards int, size int, seed int64) (*BoggleReport, error) {
+	if boards < 1 || size < 1 {
+		return nil, fmt.Errorf("number of boards and board size must be positive")
+	}
+	var dice []boggle.Die
+	var tiles map[string]int
+	var err error
+	name := "classic"
+	switch {
+	case diceFile != "":
+		dice, err = boggle.ReadDice(diceFile)
+		name = strings.TrimSuffix(path.Base(diceFile), path.Ext(diceFile))
+	case tilesFile != "":
+		tiles, err = ReadTiles(tilesFile)
+		name = strings.TrimSuffix(path.Base(tilesFile), path.Ext(tilesFile))
+	default:
+		dice = boggle.ClassicDice
+	}
+	if err != nil {
+		return nil, err
+	}
+	lex, err := lexicon.ForWordSource(ctx, wordsId)
+	if err != nil {
+		return nil, err
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	report := BoggleReport{}
+	dead := 0
+	for i := range boards {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var board *boggle.Board
+		if tiles != nil {
+			board, err = boggle.DrawTiles(rng, tiles, size, size)
+		} else {
+			board, err = boggle.RollDice(rng, dice, size, size)
+		}
+		if err != nil {
+			return nil, err
+		}
+		solution := boggle.Solve(board, lex, boggle.MinLength)
+		report.Boards = append(report.Boards, BoggleBoard{Rows: board.Rows(), Solution: solution})
+		report.MeanWords += float64(len(solution.Words))
+		report.MeanPoints += float64(solution.Points)
+		if len(solution.Words) == 0 {
+			dead++
+		}
+		if (i+1)%100 == 0 {
+			fmt.Fprintf(os.Stderr, "Solved %d boards\n", i+1)
+		}
+	}
+	report.MeanWords /= float64(boards)
+	report.MeanPoints /= float64(boards)
+	report.DeadBoards = float64(dead) / float64(boards)
+
+	outputFile, err := utils.BoggleFile(fmt.Sprintf("%s-%s-%dx%d", name, wordsId, size, size))
+	if err != nil {
+		return nil, err
+	}
+	return &report, writeJson(outputFile, report)
+}
+
+func init() {
+	utils.RegisterCommand(&utils.Command{
+		Name:    "boggle",
+		Args:    []string{"words"},
+		Summary: "Generate Boggle boards and find every word on them",
+		Description: `Generates Boggle boards by rolling the classic dice, the dice in a JSON file, or by drawing
+from a tiles JSON file, and finds every word of the word source on each board, storing the
+boards, their words, and points as a JSON file in the data directory`,
+		Setup: func(flags *flag.FlagSet) func(ctx context.Context, args []string) error {
+			diceFile := flags.String("dice", "", "JSON file of dice to roll boards from")
+			tilesFile := flags.String("tiles", "", "Tiles JSON file to draw boards from")
+			boards := flags.Int("boards", 100, "Number of boards to generate")
+			size := flags.Int("size", 4, "Width and height of the boards")
+			seed := flags.Int64("seed", 1, "Seed for generating boards")
+			return func(ctx context.Context, args []string) error {
+				report, err := SimulateBoggle(ctx, sources.WordSourceId(args[0]), *diceFile, *tilesFile, *boards, *size, *seed)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Mean words per board: %.2f, mean points per board: %.2f\n", report.MeanWords, report.MeanPoints)
+				return nil
+			}
+		},
+	})
+}
@@ -0,0 +1,188 @@
+package processes
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand"
+	"slices"
+
+	"github.com/digitaltembo/motli/packages/corpus/boggle"
+	"github.com/digitaltembo/motli/packages/corpus/lexicon"
+	"github.com/digitaltembo/motli/packages/corpus/sources"
+	"github.com/digitaltembo/motli/packages/corpus/utils"
+)
+
+// Result of designing a set of Boggle dice
+type DiceDesign struct {
+	// Best set of dice found
+	Dice []boggle.Die `json:"dice"`
+	// Average number of words found on a board rolled from the best set of dice
+	Objective float64 `json:"objective"`
+	// Objective of the starting set of dice
+	InitialObjective float64 `json:"initialObjective"`
+	// Objective of the current and best sets of dice after each iteration
+	History []OptimizationStep `json:"history"`
+}
+
+// Designs dice for a square Boggle board of diceCount dice with faces faces each. Faces are first
+// given letters in proportion to their usage in the language (as in TileSet), dealt from most to least
+// common across the dice so that no die is stacked with common letters, and then simulated annealing
+// swaps faces between dice to maximize the average number of words of the word source found on boards
+// rolled at random. Every set of dice is evaluated against the same seeded rolls, so results are
+// reproducible. Saves the dice as a JSON array of dice, each an array of faces (as read by boggle.ReadDice),
+// along with the design and the history of the search as JSON
+func DesignDice(ctx context.Context, language sources.LanguageSourceId, wordsId sources.WordSourceId, diceCount int, faces int, boards int, iterations int, seed int64) (*DiceDesign, error) {
+	size := int(math.Round(math.Sqrt(float64(diceCount))))
+	if diceCount < 1 || size*size != diceCount {
+		return nil, fmt.Errorf("number of dice must be a positive square, got %d", diceCount)
+	}
+	if faces < 1 || boards < 1 || iterations < 0 {
+		return nil, fmt.Errorf("faces and number of boards must be positive, and iterations non-negative")
+	}
+	analysis, err := AnalyzeNgrams(ctx, AnalysisMode_Usage, language, "", NgramRange{Min: 1, Max: 1}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	for _, a := range analysis {
+		counts[a.Symbol] = a.CorpusCounts.Count
+	}
+	if len(counts) == 0 {
+		return nil, fmt.Errorf("no letters found in the %s corpus", language)
+	}
+	dice := dealDice(counts, diceCount, faces)
+
+	lex, err := lexicon.ForWordSource(ctx, wordsId)
+	if err != nil {
+		return nil, err
+	}
+	objective := func() float64 {
+		rng := rand.New(rand.NewSource(seed))
+		found := 0
+		for range boards {
+			board, _ := boggle.RollDice(rng, dice, size, size)
+			found += len(boggle.Solve(board, lex, boggle.MinLength).Words)
+		}
+		return float64(found) / float64(boards)
+	}
+
+	design := DiceDesign{Dice: cloneDice(dice)}
+	design.InitialObjective, design.Objective, design.History, err = anneal(ctx,
+		rand.New(rand.NewSource(seed)),
+		iterations,
+		objective,
+		func(rng *rand.Rand) func() {
+			a, b := rng.Intn(diceCount), rng.Intn(diceCount)
+			i, j := rng.Intn(faces), rng.Intn(faces)
+			if a == b || dice[a][i] == dice[b][j] {
+				return nil
+			}
+			dice[a][i], dice[b][j] = dice[b][j], dice[a][i]
+			return func() {
+				dice[a][i], dice[b][j] = dice[b][j], dice[a][i]
+			}
+		},
+		func() { design.Dice = cloneDice(dice) })
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("%s-%s-%dx%d", language, wordsId, diceCount, faces)
+	diceFile, err := utils.DiceFile(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeJson(diceFile, design.Dice); err != nil {
+		return nil, err
+	}
+	designFile, err := utils.DiceFile(name + "-design")
+	if err != nil {
+		return nil, err
+	}
+	return &design, writeJson(designFile, design)
+}
+
+// Assigns diceCount*faces faces to letters in proportion to their counts, and deals them from the most
+// to the least common letter round-robin across the dice, with ties broken alphabetically
+func dealDice(counts map[string]int, diceCount int, faces int) []boggle.Die {
+	total := diceCount * faces
+	letters := distributeTiles(counts, total)
+	byCount := slices.SortedFunc(maps.Keys(letters), func(a, b string) int {
+		if c := cmp.Compare(counts[b], counts[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	// distributeTiles only approximates the total, so pad the most common letters, or trim whichever
+	// letters have the most faces (the least common of them first, so rare letters go first when short)
+	assigned := 0
+	for _, letter := range byCount {
+		assigned += letters[letter]
+	}
+	for i := 0; assigned < total; i = (i + 1) % len(byCount) {
+		letters[byCount[i]]++
+		assigned++
+	}
+	for ; assigned > total; assigned-- {
+		most := byCount[len(byCount)-1]
+		for _, letter := range slices.Backward(byCount) {
+			if letters[letter] > letters[most] {
+				most = letter
+			}
+		}
+		letters[most]--
+	}
+
+	dice := make([]boggle.Die, diceCount)
+	dealt := 0
+	for _, letter := range byCount {
+		for range letters[letter] {
+			dice[dealt%diceCount] = append(dice[dealt%diceCount], letter)
+			dealt++
+		}
+	}
+	return dice
+}
+
+func cloneDice(dice []boggle.Die) []boggle.Die {
+	cloned := make([]boggle.Die, len(dice))
+	for i, die := range dice {
+		cloned[i] = slices.Clone(die)
+	}
+	return cloned
+}
+
+func init() {
+	utils.RegisterCommand(&utils.Command{
+		Name:    "boggle dice",
+		Args:    []string{"language"},
+		Summary: "Design Boggle dice from the letter usage of a language",
+		Description: `Designs dice for a square Boggle board, starting from faces in proportion to the letter usage
+of the language and searching (by simulated annealing) for swaps of faces between dice that
+maximize the average number of words of the word source found on boards rolled at random,
+storing the dice as a JSON file in the data directory that can be passed to "boggle --dice"`,
+		Setup: func(flags *flag.FlagSet) func(ctx context.Context, args []string) error {
+			words := flags.String("words", "", "Word source of words which can be found on the boards (required)")
+			dice := flags.Int("count", 16, "Number of dice, which must be a square number")
+			faces := flags.Int("faces", 6, "Number of faces of each die")
+			boards := flags.Int("boards", 100, "Number of boards rolled to evaluate each set of dice")
+			iterations := flags.Int("iterations", 1000, "Number of steps to optimize for")
+			seed := flags.Int64("seed", 1, "Seed for rolling boards")
+			return func(ctx context.Context, args []string) error {
+				if *words == "" {
+					return fmt.Errorf("the --words flag is required")
+				}
+				design, err := DesignDice(ctx, sources.LanguageSourceId(args[0]), sources.WordSourceId(*words), *dice, *faces, *boards, *iterations, *seed)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Mean words per board improved from %.2f to %.2f\n", design.InitialObjective, design.Objective)
+				return nil
+			}
+		},
+	})
+}
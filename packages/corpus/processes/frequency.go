@@ -0,0 +1,155 @@
+package processes
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/digitaltembo/motli/packages/corpus/sources"
+	"github.com/digitaltembo/motli/packages/corpus/utils"
+)
+
+// Counts the number of times each word of the word source is used in the language source,
+// and saves the resulting frequency table as a csv. Words which are never used are left out
+func WordFrequencies(ctx context.Context, languageId sources.LanguageSourceId, wordsId sources.WordSourceId) (map[string]int, error) {
+	freqs, built, err := wordFrequencies(ctx, languageId, wordsId, nil)
+	if err == nil && !built {
+		fmt.Fprintf(os.Stderr, "Already counted frequencies!\n")
+	}
+	return freqs, err
+}
+
+// Reads the frequency table if it is up to date, otherwise computes it from the word source (loading
+// it if it is nil). Returns whether the table was computed
+func wordFrequencies(ctx context.Context, languageId sources.LanguageSourceId, wordsId sources.WordSourceId, ws sources.WordSource) (map[string]int, bool, error) {
+	outputFile, err := utils.FrequencyFile(string(languageId), string(wordsId))
+	if err != nil {
+		return nil, false, err
+	}
+	params := map[string]string{"language": string(languageId), "words": string(wordsId)}
+	var freqs map[string]int
+	built, err := utils.BuildArtifact(ctx, outputFile, params, func(ctx context.Context) error {
+		var err error
+		if ws != nil {
+			// the word source was loaded outside of the build, so its files are recorded here
+			files, err := sources.WordSourceFiles(wordsId)
+			if err != nil {
+				return err
+			}
+			utils.RecordInput(ctx, files...)
+		} else if ws, err = sources.GetWordSource(ctx, wordsId); err != nil {
+			return err
+		}
+		freqs, err = countFrequencies(ctx, outputFile, languageId, ws)
+		return err
+	})
+	if err != nil || built {
+		return freqs, built, err
+	}
+	freqs, err = readFrequencies(outputFile)
+	return freqs, false, err
+}
+
+// Loads the word source, with the Freq of each word populated by its usage in the language source
+func FrequencyWordSource(ctx context.Context, languageId sources.LanguageSourceId, wordsId sources.WordSourceId) (sources.WordSource, error) {
+	ws, err := sources.GetWordSource(ctx, wordsId)
+	if err != nil {
+		return nil, err
+	}
+	freqs, _, err := wordFrequencies(ctx, languageId, wordsId, ws)
+	if err != nil {
+		return nil, err
+	}
+	return sources.WithFrequencies(ws, freqs), nil
+}
+
+// Streams the language source, counting the words found in the word source, and saves the
+// counts to the output file
+func countFrequencies(ctx context.Context, outputFile string, languageId sources.LanguageSourceId, ws sources.WordSource) (map[string]int, error) {
+	language, err := sources.GetLanguageSource(ctx, languageId)
+	if err != nil {
+		return nil, err
+	}
+	freqs := map[string]int{}
+	analyzed := 0
+	for token, err := range language.Read(ctx) {
+		// a partial count is never saved, as it would be mistaken for a complete one
+		if err != nil {
+			return nil, err
+		}
+		analyzed++
+		if analyzed%100000 == 0 {
+			fmt.Fprintf(os.Stderr, "Counted %d words (latest: %s)\n", analyzed, token)
+		}
+		if word := ws.GetWord(token); word != nil {
+			freqs[word.Word]++
+		}
+	}
+
+	output, err := os.Create(outputFile)
+	if err != nil {
+		return nil, err
+	}
+	defer output.Close()
+
+	csvWriter := csv.NewWriter(output)
+	if err := csvWriter.Write([]string{"word", "freq"}); err != nil {
+		return nil, err
+	}
+	for _, word := range slices.Sorted(maps.Keys(freqs)) {
+		if err := csvWriter.Write([]string{word, strconv.Itoa(freqs[word])}); err != nil {
+			return nil, err
+		}
+	}
+	csvWriter.Flush()
+	return freqs, csvWriter.Error()
+}
+
+// Reads a frequency table csv as written by countFrequencies
+func readFrequencies(file string) (map[string]int, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("frequency table %s is empty", file)
+	}
+	freqs := map[string]int{}
+	for _, record := range records[1:] {
+		if len(record) > 1 {
+			freqs[record[0]], _ = strconv.Atoi(record[1])
+		}
+	}
+	return freqs, nil
+}
+
+func init() {
+	utils.RegisterCommand(&utils.Command{
+		Name:    "analyze frequencies",
+		Args:    []string{"language"},
+		Summary: "Count the usage of the words of a word source in a language",
+		Description: `Counts the number of times each word of the word source is used in the language, storing
+the frequency table as a csv in the data directory. The table is used to populate Word.Freq`,
+		Setup: func(flags *flag.FlagSet) func(ctx context.Context, args []string) error {
+			words := flags.String("words", "", "Word source whose words' usage is counted (required)")
+			return func(ctx context.Context, args []string) error {
+				if *words == "" {
+					return fmt.Errorf("the --words flag is required")
+				}
+				_, err := WordFrequencies(ctx, sources.LanguageSourceId(args[0]), sources.WordSourceId(*words))
+				return err
+			}
+		},
+	})
+}
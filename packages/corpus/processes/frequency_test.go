@@ -0,0 +1,30 @@
+package processes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadFrequencies(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "freqs.csv")
+	if err := os.WriteFile(file, []byte("word,freq\napple,3\nbee,1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	freqs, err := readFrequencies(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(freqs) != 2 || freqs["apple"] != 3 || freqs["bee"] != 1 {
+		t.Errorf("read frequencies %v", freqs)
+	}
+
+	empty := filepath.Join(dir, "empty.csv")
+	if err := os.WriteFile(empty, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readFrequencies(empty); err == nil {
+		t.Errorf("read an empty frequency table")
+	}
+}
@@ -0,0 +1,32 @@
+package processes
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/digitaltembo/motli/packages/corpus/lexicon"
+	"github.com/digitaltembo/motli/packages/corpus/sources"
+	"github.com/digitaltembo/motli/packages/corpus/utils"
+)
+
+func init() {
+	utils.RegisterCommand(&utils.Command{
+		Name:    "lexicon",
+		Args:    []string{"words"},
+		Summary: "Compile a word source into a lexicon",
+		Description: `Compiles the word source into a minimized DAWG and GADDAG for fast prefix and anchor lookups
+by game solvers, storing them as a binary file in the data directory`,
+		Setup: func(flags *flag.FlagSet) func(ctx context.Context, args []string) error {
+			return func(ctx context.Context, args []string) error {
+				lex, err := lexicon.ForWordSource(ctx, sources.WordSourceId(args[0]))
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Lexicon has a DAWG of %d nodes and a GADDAG of %d nodes\n",
+					lex.Dawg.NodeCount(), lex.Gaddag.NodeCount())
+				return nil
+			}
+		},
+	})
+}
@@ -0,0 +1,217 @@
+package processes
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/digitaltembo/motli/packages/corpus/anagram"
+	"github.com/digitaltembo/motli/packages/corpus/sources"
+	"github.com/digitaltembo/motli/packages/corpus/utils"
+)
+
+// Result of optimizing a distribution of tiles for playability
+type OptimizedTiles struct {
+	// Best distribution of tiles found
+	Tiles map[string]int `json:"tiles"`
+	// Average number of words formable from a rack of the best distribution of tiles
+	Objective float64 `json:"objective"`
+	// Objective of the starting distribution of tiles
+	InitialObjective float64 `json:"initialObjective"`
+	// Objective of the current and best distributions after each iteration
+	History []OptimizationStep `json:"history"`
+}
+
+// Progress of the optimizer after a single iteration
+type OptimizationStep struct {
+	Current float64 `json:"current"`
+	Best    float64 `json:"best"`
+}
+
+// Starting temperature of the annealing, as a fraction of the initial objective
+const initialTemperature = 0.05
+
+// Uses simulated annealing to search for a distribution of tiles, with the same total number of tiles
+// as the one in tilesFile, which maximizes the average number of words of the word source that can be
+// formed from racks drawn at random. Each step moves a single tile from one letter to another, never
+// going below the minimum count of a letter (1 by default, to keep every letter of the starting set).
+// Randomness is seeded, and every distribution is evaluated against the same sequence of draws, so
+// results are reproducible. Saves the best distribution along with the history of the objective as JSON
+func OptimizeTiles(ctx context.Context, tilesFile string, wordsId sources.WordSourceId, rackSize int, racks int, iterations int, minimums map[string]int, seed int64) (*OptimizedTiles, error) {
+	if rackSize < 1 || racks < 1 || iterations < 0 {
+		return nil, fmt.Errorf("rack size and number of racks must be positive, and iterations non-negative")
+	}
+	tiles, err := ReadTiles(tilesFile)
+	if err != nil {
+		return nil, err
+	}
+	minimums = maps.Clone(minimums)
+	if minimums == nil {
+		minimums = map[string]int{}
+	}
+	for tile := range minimums {
+		if _, ok := tiles[tile]; !ok {
+			// tiles can be moved into letters which only have a minimum
+			tiles[tile] = 0
+		}
+	}
+	total := 0
+	for tile, count := range tiles {
+		total += count
+		if _, ok := minimums[tile]; !ok {
+			minimums[tile] = min(count, 1)
+		}
+		if count < minimums[tile] {
+			return nil, fmt.Errorf("starting tiles have fewer than the minimum %d of %s", minimums[tile], tile)
+		}
+	}
+	if total < rackSize {
+		return nil, fmt.Errorf("cannot draw racks of %d from %d tiles", rackSize, total)
+	}
+	ws, err := sources.GetWordSource(ctx, wordsId)
+	if err != nil {
+		return nil, err
+	}
+	index := anagram.NewIndex(ws)
+
+	objective := func(tiles map[string]int) float64 {
+		rng := rand.New(rand.NewSource(seed))
+		bag := tileBag(tiles, 0)
+		formable := 0
+		for range racks {
+			formable += index.Count(drawRack(rng, bag, rackSize), anagram.Options{})
+		}
+		return float64(formable) / float64(racks)
+	}
+
+	letters := slices.Sorted(maps.Keys(tiles))
+	current := maps.Clone(tiles)
+	result := OptimizedTiles{Tiles: maps.Clone(current)}
+	result.InitialObjective, result.Objective, result.History, err = anneal(ctx,
+		rand.New(rand.NewSource(seed)),
+		iterations,
+		func() float64 { return objective(current) },
+		func(rng *rand.Rand) func() {
+			from, to := letters[rng.Intn(len(letters))], letters[rng.Intn(len(letters))]
+			if from == to || current[from] <= minimums[from] {
+				return nil
+			}
+			current[from]--
+			current[to]++
+			return func() {
+				current[from]++
+				current[to]--
+			}
+		},
+		func() { result.Tiles = maps.Clone(current) })
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("%s-%s-k%d",
+		strings.TrimSuffix(path.Base(tilesFile), path.Ext(tilesFile)), wordsId, rackSize)
+	outputFile, err := utils.OptimizedTilesFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return &result, writeJson(outputFile, result)
+}
+
+// Runs simulated annealing for the number of iterations, maximizing the objective of some state. Each
+// step calls propose to make a random change to the state, which returns a function undoing the change
+// (or nil if it made no change), and the change is kept if it improves the objective or, with a probability
+// that shrinks as the temperature cools, if it makes it worse. Calls improved whenever the state is the
+// best found so far. Returns the initial and best objectives, and the objectives after every iteration,
+// or the error of the context if it is cancelled first
+func anneal(ctx context.Context, rng *rand.Rand, iterations int, objective func() float64, propose func(*rand.Rand) func(), improved func()) (float64, float64, []OptimizationStep, error) {
+	currentObjective := objective()
+	initialObjective, bestObjective := currentObjective, currentObjective
+	temperature := initialTemperature * currentObjective
+	history := []OptimizationStep{}
+
+	for i := range iterations {
+		if err := ctx.Err(); err != nil {
+			return 0, 0, nil, err
+		}
+		if undo := propose(rng); undo != nil {
+			candidateObjective := objective()
+			cooled := temperature * (1 - float64(i)/float64(iterations))
+			delta := candidateObjective - currentObjective
+			if delta >= 0 || (cooled > 0 && rng.Float64() < math.Exp(delta/cooled)) {
+				currentObjective = candidateObjective
+				if currentObjective > bestObjective {
+					bestObjective = currentObjective
+					improved()
+				}
+			} else {
+				undo()
+			}
+		}
+		history = append(history, OptimizationStep{Current: currentObjective, Best: bestObjective})
+		if (i+1)%10 == 0 {
+			fmt.Fprintf(os.Stderr, "Optimized for %d iterations (current: %.3f, best: %.3f)\n",
+				i+1, currentObjective, bestObjective)
+		}
+	}
+	return initialObjective, bestObjective, history, nil
+}
+
+// Parses a comma-separated list of tile counts, e.g. "q=1,z=1"
+func ParseTileCounts(s string) (map[string]int, error) {
+	counts := map[string]int{}
+	if s == "" {
+		return counts, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		tile, countStr, ok := strings.Cut(part, "=")
+		count, err := strconv.Atoi(countStr)
+		if !ok || tile == "" || err != nil {
+			return nil, fmt.Errorf("invalid tile count %q, expected tile=count", part)
+		}
+		counts[strings.ToLower(tile)] = count
+	}
+	return counts, nil
+}
+
+func init() {
+	utils.RegisterCommand(&utils.Command{
+		Name:    "optimize",
+		Args:    []string{"tiles file"},
+		Summary: "Search for the distribution of tiles forming the most words",
+		Description: `Searches (by simulated annealing) for a distribution with the same number of tiles as the
+tiles JSON file that maximizes the average number of words formable from random racks, keeping
+at least one of each tile or the comma-separated minimums (e.g. "q=1,z=1"), storing the best
+distribution and the history of the search as a JSON file in the data directory`,
+		Setup: func(flags *flag.FlagSet) func(ctx context.Context, args []string) error {
+			words := flags.String("words", "", "Word source of words which can be formed (required)")
+			rackSize := flags.Int("rack", 7, "Number of tiles in each rack")
+			racks := flags.Int("racks", 200, "Number of racks drawn to evaluate each distribution of tiles")
+			iterations := flags.Int("iterations", 1000, "Number of steps to optimize for")
+			minimums := flags.String("min", "", "Comma-separated minimum counts of tiles, e.g. q=1,z=1")
+			seed := flags.Int64("seed", 1, "Seed for random draws")
+			return func(ctx context.Context, args []string) error {
+				if *words == "" {
+					return fmt.Errorf("the --words flag is required")
+				}
+				parsedMinimums, err := ParseTileCounts(*minimums)
+				if err != nil {
+					return err
+				}
+				result, err := OptimizeTiles(ctx, args[0], sources.WordSourceId(*words), *rackSize, *racks, *iterations, parsedMinimums, *seed)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Mean words per rack improved from %.2f to %.2f\n", result.InitialObjective, result.Objective)
+				return nil
+			}
+		},
+	})
+}
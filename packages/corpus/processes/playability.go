@@ -0,0 +1,187 @@
+package processes
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/digitaltembo/motli/packages/corpus/anagram"
+	"github.com/digitaltembo/motli/packages/corpus/sources"
+	"github.com/digitaltembo/motli/packages/corpus/utils"
+)
+
+// Summary of how many words of a word source can be formed from racks drawn at random from a set of tiles
+type PlayabilityReport struct {
+	// Number of racks drawn
+	Racks int `json:"racks"`
+	// Number of tiles in each rack
+	RackSize int `json:"rackSize"`
+	// Number of blanks added to the set of tiles
+	Blanks int `json:"blanks"`
+	// Average number of words formable from a rack
+	Mean float64 `json:"mean"`
+	// Median number of words formable from a rack
+	Median float64 `json:"median"`
+	// Number of words formable at various percentiles of racks, keyed like "p10"
+	Percentiles map[string]float64 `json:"percentiles"`
+	// Fraction of racks from which no words can be formed
+	DeadRacks float64 `json:"deadRacks"`
+}
+
+// Percentiles reported in the PlayabilityReport
+var playabilityPercentiles = []int{5, 10, 25, 75, 90, 95}
+
+// Draws racks of rackSize tiles at random from the set of tiles in tilesFile (as written by TileSet),
+// plus the provided number of blanks, and measures how many words of the word source each rack could
+// form, saving a report of the results as JSON in the data directory
+func SimulatePlayability(ctx context.Context, tilesFile string, wordsId sources.WordSourceId, rackSize int, racks int, blanks int, seed int64) (*PlayabilityReport, error) {
+	if rackSize < 1 || racks < 1 {
+		return nil, fmt.Errorf("rack size and number of racks must be positive")
+	}
+	tiles, err := ReadTiles(tilesFile)
+	if err != nil {
+		return nil, err
+	}
+	bag := tileBag(tiles, blanks)
+	if len(bag) < rackSize {
+		return nil, fmt.Errorf("cannot draw racks of %d from %d tiles", rackSize, len(bag))
+	}
+	ws, err := sources.GetWordSource(ctx, wordsId)
+	if err != nil {
+		return nil, err
+	}
+	index := anagram.NewIndex(ws)
+
+	rng := rand.New(rand.NewSource(seed))
+	formable := make([]int, racks)
+	for i := range racks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		formable[i] = index.Count(drawRack(rng, bag, rackSize), anagram.Options{})
+		if (i+1)%100 == 0 {
+			fmt.Fprintf(os.Stderr, "Simulated %d racks\n", i+1)
+		}
+	}
+
+	report := summarizePlayability(formable)
+	report.RackSize = rackSize
+	report.Blanks = blanks
+
+	name := fmt.Sprintf("%s-%s-k%d-b%d",
+		strings.TrimSuffix(path.Base(tilesFile), path.Ext(tilesFile)), wordsId, rackSize, blanks)
+	outputFile, err := utils.PlayabilityFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return report, writeJson(outputFile, report)
+}
+
+// Reads a set of tiles, mapping each tile to the number of copies of it, from a JSON file
+func ReadTiles(tilesFile string) (map[string]int, error) {
+	contents, err := os.ReadFile(tilesFile)
+	if err != nil {
+		return nil, err
+	}
+	tiles := map[string]int{}
+	if err := json.Unmarshal(contents, &tiles); err != nil {
+		return nil, err
+	}
+	return tiles, nil
+}
+
+// Every tile in the set, along with the blanks, in a deterministic order
+func tileBag(tiles map[string]int, blanks int) []string {
+	bag := []string{}
+	for _, tile := range slices.Sorted(maps.Keys(tiles)) {
+		for range tiles[tile] {
+			bag = append(bag, strings.ToLower(tile))
+		}
+	}
+	for range blanks {
+		bag = append(bag, anagram.Blank)
+	}
+	return bag
+}
+
+// Draws size tiles from the bag without replacement, counting the copies of each tile drawn
+func drawRack(rng *rand.Rand, bag []string, size int) anagram.Rack {
+	rack := anagram.Rack{}
+	for _, i := range rng.Perm(len(bag))[:size] {
+		rack[bag[i]]++
+	}
+	return rack
+}
+
+// Summary statistics of the number of words formable from each rack
+func summarizePlayability(formable []int) *PlayabilityReport {
+	sorted := slices.Clone(formable)
+	slices.Sort(sorted)
+
+	total, dead := 0, 0
+	for _, f := range sorted {
+		total += f
+		if f == 0 {
+			dead++
+		}
+	}
+	report := PlayabilityReport{
+		Racks:       len(sorted),
+		Mean:        float64(total) / float64(len(sorted)),
+		Median:      percentile(sorted, 50),
+		Percentiles: map[string]float64{},
+		DeadRacks:   float64(dead) / float64(len(sorted)),
+	}
+	for _, p := range playabilityPercentiles {
+		report.Percentiles[fmt.Sprintf("p%d", p)] = percentile(sorted, p)
+	}
+	return &report
+}
+
+// The pth percentile of the sorted values, linearly interpolating between the closest ranks
+func percentile(sorted []int, p int) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := float64(p) / 100 * float64(len(sorted)-1)
+	lower, upper := int(math.Floor(rank)), int(math.Ceil(rank))
+	weight := rank - float64(lower)
+	return float64(sorted[lower])*(1-weight) + float64(sorted[upper])*weight
+}
+
+func init() {
+	utils.RegisterCommand(&utils.Command{
+		Name:    "playability",
+		Args:    []string{"tiles file"},
+		Summary: "Count the words formable from racks drawn from a set of tiles",
+		Description: `Draws racks of tiles at random from the tiles JSON file, plus an optional number of blanks,
+and counts how many words of the word source each rack can form, storing the mean/median/
+percentiles and fraction of dead racks as a JSON file in the data directory`,
+		Setup: func(flags *flag.FlagSet) func(ctx context.Context, args []string) error {
+			words := flags.String("words", "", "Word source of words which can be formed (required)")
+			rackSize := flags.Int("rack", 7, "Number of tiles in each rack")
+			racks := flags.Int("racks", 1000, "Number of racks to draw")
+			blanks := flags.Int("blanks", 0, "Number of blank tiles to add to the tiles")
+			seed := flags.Int64("seed", 1, "Seed for random draws")
+			return func(ctx context.Context, args []string) error {
+				if *words == "" {
+					return fmt.Errorf("the --words flag is required")
+				}
+				report, err := SimulatePlayability(ctx, args[0], sources.WordSourceId(*words), *rackSize, *racks, *blanks, *seed)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Mean words per rack: %.2f, dead racks: %.2f%%\n", report.Mean, report.DeadRacks*100)
+				return nil
+			}
+		},
+	})
+}
@@ -0,0 +1,271 @@
+package processes
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/digitaltembo/motli/packages/corpus/sources"
+	"github.com/digitaltembo/motli/packages/corpus/utils"
+)
+
+// Model used for assigning point values to tiles
+type ScoreModel string
+
+const (
+	// Scores following the rank of how rare the letter is, 1/P(l), spread evenly over the range.
+	// Scaling 1/P(l) itself would leave almost every letter at 1, as the rarest few dominate it,
+	// and scaling its logarithm is the entropy model
+	ScoreModel_InverseFrequency = "inverse"
+	// Scores in the style of valett (https://github.com/jmlewis/valett), combining how rare the
+	// letter is, how rarely it is useful in short words, and how hard it is to place next to
+	// other letters (the entropy of its neighbors in the word list)
+	ScoreModel_Valett = "valett"
+	// Scores proportional to the information content of the letter, -log2(P(l))
+	ScoreModel_Entropy = "entropy"
+)
+
+// Lengths of words considered "short" for the valett model, weighted by 1/length
+const (
+	valettMinLength = 2
+	valettMaxLength = 8
+)
+
+// Computes the point value of each letter of the language, from 1 to maxScore, using the
+// ngram analysis of the language and (for the valett model) the words of the word source,
+// and saves the scores as JSON in the data directory
+func TileScores(ctx context.Context, model ScoreModel, language sources.LanguageSourceId, wordsId sources.WordSourceId, maxScore int) (map[string]int, error) {
+	if maxScore < 1 {
+		return nil, fmt.Errorf("max score must be at least 1")
+	}
+	analysis, err := AnalyzeNgrams(ctx, AnalysisMode_Usage, language, "", NgramRange{Min: 1, Max: 1}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	probabilities := letterProbabilities(analysis)
+
+	var values map[string]float64
+	name := fmt.Sprintf("%s-%s", language, model)
+	switch model {
+	case ScoreModel_InverseFrequency:
+		values = rankValues(inverseFrequencyValues(probabilities))
+	case ScoreModel_Entropy:
+		values = entropyValues(probabilities)
+	case ScoreModel_Valett:
+		if wordsId == "" {
+			return nil, fmt.Errorf("valett scores require a word source")
+		}
+		ws, err := sources.GetWordSource(ctx, wordsId)
+		if err != nil {
+			return nil, err
+		}
+		values = valettValues(probabilities, ws.GetWordList())
+		name = fmt.Sprintf("%s-%s-%s", language, wordsId, model)
+	default:
+		return nil, fmt.Errorf("unsupported score model: %s", model)
+	}
+	scores := scaleScores(values, maxScore)
+
+	outputFile, err := utils.ScoreFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return scores, writeJson(outputFile, scores)
+}
+
+// Probability P(l) of each letter appearing in a word of the corpus, normalized over the analysis
+func letterProbabilities(analysis []*Analysis) map[string]float64 {
+	total := 0
+	for _, a := range analysis {
+		total += a.CorpusCounts.Count
+	}
+	probabilities := map[string]float64{}
+	for _, a := range analysis {
+		if total > 0 {
+			probabilities[a.Symbol] = float64(a.CorpusCounts.Count) / float64(total)
+		} else {
+			probabilities[a.Symbol] = 0
+		}
+	}
+	return probabilities
+}
+
+func inverseFrequencyValues(probabilities map[string]float64) map[string]float64 {
+	values := map[string]float64{}
+	for letter, p := range probabilities {
+		values[letter] = 1 / p
+	}
+	return values
+}
+
+// Rank of each value among the distinct values, from 0 for the smallest, so equal values have equal ranks
+func rankValues(values map[string]float64) map[string]float64 {
+	distinct := slices.Sorted(maps.Values(values))
+	distinct = slices.Compact(distinct)
+	ranks := map[string]float64{}
+	for letter, v := range values {
+		rank, _ := slices.BinarySearch(distinct, v)
+		ranks[letter] = float64(rank)
+	}
+	return ranks
+}
+
+func entropyValues(probabilities map[string]float64) map[string]float64 {
+	values := map[string]float64{}
+	for letter, p := range probabilities {
+		values[letter] = -math.Log2(p)
+	}
+	return values
+}
+
+// Equal-weighted combination of the rarity of each letter, how rarely it is useful in short
+// words, and how predictable (low entropy) its neighbors are, each normalized to [0, 1]
+func valettValues(probabilities map[string]float64, words []*sources.Word) map[string]float64 {
+	// fraction of words of each short length containing the letter
+	wordsOfLength := map[int]int{}
+	containing := map[string]map[int]int{}
+	// counts of the letters next to each letter
+	neighbors := map[string]map[string]int{}
+	for letter := range probabilities {
+		containing[letter] = map[int]int{}
+		neighbors[letter] = map[string]int{}
+	}
+
+	for _, w := range words {
+		letters := strings.Split(strings.ToLower(w.Word), "")
+		if !allIn(letters, probabilities) {
+			continue
+		}
+		for i, letter := range letters {
+			if i > 0 {
+				neighbors[letter][letters[i-1]]++
+			}
+			if i < len(letters)-1 {
+				neighbors[letter][letters[i+1]]++
+			}
+		}
+		if len(letters) < valettMinLength || len(letters) > valettMaxLength {
+			continue
+		}
+		wordsOfLength[len(letters)]++
+		seen := map[string]bool{}
+		for _, letter := range letters {
+			if !seen[letter] {
+				seen[letter] = true
+				containing[letter][len(letters)]++
+			}
+		}
+	}
+
+	rarity := normalize(inverseFrequencyValues(probabilities))
+	uselessness := map[string]float64{}
+	predictability := map[string]float64{}
+	for letter := range probabilities {
+		usefulness, totalWeight := 0.0, 0.0
+		for length := valettMinLength; length <= valettMaxLength; length++ {
+			if wordsOfLength[length] == 0 {
+				continue
+			}
+			weight := 1 / float64(length)
+			usefulness += weight * float64(containing[letter][length]) / float64(wordsOfLength[length])
+			totalWeight += weight
+		}
+		if totalWeight > 0 {
+			usefulness /= totalWeight
+		}
+		uselessness[letter] = 1 - usefulness
+		predictability[letter] = -shannonEntropy(neighbors[letter])
+	}
+	uselessness = normalize(uselessness)
+	predictability = normalize(predictability)
+
+	values := map[string]float64{}
+	for letter := range probabilities {
+		values[letter] = (rarity[letter] + uselessness[letter] + predictability[letter]) / 3
+	}
+	return values
+}
+
+// whether every letter is a key of the map
+func allIn(letters []string, m map[string]float64) bool {
+	for _, letter := range letters {
+		if _, ok := m[letter]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Shannon entropy in bits of the distribution described by the counts
+func shannonEntropy(counts map[string]int) float64 {
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+	entropy := 0.0
+	for _, c := range counts {
+		if c > 0 {
+			p := float64(c) / float64(total)
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy
+}
+
+// Linearly rescales the values into [0, 1], with infinite values (from letters that never occur) mapped to 1
+func normalize(values map[string]float64) map[string]float64 {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if math.IsInf(v, 0) {
+			continue
+		}
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	normalized := map[string]float64{}
+	for letter, v := range values {
+		switch {
+		case math.IsInf(v, 1):
+			normalized[letter] = 1
+		case math.IsInf(v, -1) || max <= min:
+			normalized[letter] = 0
+		default:
+			normalized[letter] = (v - min) / (max - min)
+		}
+	}
+	return normalized
+}
+
+// Rescales the values into whole number scores from 1 to maxScore
+func scaleScores(values map[string]float64, maxScore int) map[string]int {
+	scores := map[string]int{}
+	for letter, v := range normalize(values) {
+		scores[letter] = 1 + int(math.Round(v*float64(maxScore-1)))
+	}
+	return scores
+}
+
+func init() {
+	utils.RegisterCommand(&utils.Command{
+		Name:    "scores",
+		Args:    []string{"language"},
+		Summary: "Compute a point value for each tile of a language",
+		Description: `Runs analysis of ngram size of 1 and computes a point value from 1 to max-score for each
+tile, storing the results as a JSON file in the data directory. Models are "inverse" (rank of
+rarity), "entropy" (information content) and "valett" (rarity, usefulness in short words and
+ease of placement, which requires a word source)`,
+		Setup: func(flags *flag.FlagSet) func(ctx context.Context, args []string) error {
+			model := flags.String("model", ScoreModel_InverseFrequency, "Model used to compute scores: inverse, entropy or valett")
+			words := flags.String("words", "", "Word source of the valett model")
+			maxScore := flags.Int("max-score", 10, "Point value of the most valuable tile")
+			return func(ctx context.Context, args []string) error {
+				_, err := TileScores(ctx, ScoreModel(*model), sources.LanguageSourceId(args[0]), sources.WordSourceId(*words), *maxScore)
+				return err
+			}
+		},
+	})
+}
@@ -0,0 +1,52 @@
+package processes
+
+import "testing"
+
+// Rough counts of English words containing each letter
+var testLetterCounts = map[string]int{
+	"e": 1270, "t": 906, "a": 817, "o": 751, "i": 697, "n": 675, "s": 633, "h": 609, "r": 599,
+	"d": 425, "l": 403, "c": 278, "u": 276, "m": 241, "w": 236, "f": 223, "g": 202, "y": 197,
+	"p": 193, "b": 149, "v": 98, "k": 77, "j": 15, "x": 15, "q": 10, "z": 7,
+}
+
+func testLetterProbabilities() map[string]float64 {
+	analysis := []*Analysis{}
+	for letter, count := range testLetterCounts {
+		analysis = append(analysis, &Analysis{Symbol: letter, CorpusCounts: Counts{Count: count}})
+	}
+	return letterProbabilities(analysis)
+}
+
+func TestInverseScoresSpread(t *testing.T) {
+	scores := scaleScores(rankValues(inverseFrequencyValues(testLetterProbabilities())), 10)
+	if scores["e"] != 1 || scores["z"] != 10 {
+		t.Errorf("e scores %d and z scores %d, want 1 and 10", scores["e"], scores["z"])
+	}
+	// each score is shared by only a few letters
+	letters := map[int]int{}
+	for _, score := range scores {
+		letters[score]++
+	}
+	for score := 1; score <= 10; score++ {
+		if letters[score] == 0 || letters[score] > 4 {
+			t.Errorf("%d letters score %d", letters[score], score)
+		}
+	}
+	for rarer, count := range testLetterCounts {
+		for common, commonCount := range testLetterCounts {
+			if count < commonCount && scores[rarer] < scores[common] {
+				t.Errorf("%s scores %d, less than the more common %s scoring %d", rarer, scores[rarer], common, scores[common])
+			}
+		}
+	}
+}
+
+func TestRankValuesTies(t *testing.T) {
+	ranks := rankValues(map[string]float64{"a": 0.5, "b": 2, "c": 0.5, "d": 7})
+	want := map[string]float64{"a": 0, "b": 1, "c": 0, "d": 2}
+	for letter, rank := range want {
+		if ranks[letter] != rank {
+			t.Errorf("rank of %s is %v, want %v", letter, ranks[letter], rank)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package processes
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/digitaltembo/motli/packages/corpus/anagram"
+	"github.com/digitaltembo/motli/packages/corpus/board"
+	"github.com/digitaltembo/motli/packages/corpus/lexicon"
+	"github.com/digitaltembo/motli/packages/corpus/sources"
+	"github.com/digitaltembo/motli/packages/corpus/utils"
+)
+
+// Number of players of each self-play game
+const selfPlayPlayers = 2
+
+// Number of tiles in each player's rack
+const selfPlayRackSize = 7
+
+// Number of turns in a row without a score after which a game ends
+const selfPlayScorelessTurns = 6
+
+// Summary of games of Scrabble played between greedy players
+type SelfPlayReport struct {
+	// Number of games played
+	Games int `json:"games"`
+	// Average final score of a player in a game
+	MeanScore float64 `json:"meanScore"`
+	// Variance of the final score of a player in a game
+	ScoreVariance float64 `json:"scoreVariance"`
+	// Average number of turns in a game, including exchanges and passes
+	MeanTurns float64 `json:"meanTurns"`
+	// Fraction of moves played which were bingos
+	BingoRate float64 `json:"bingoRate"`
+	// Average number of bingos played in a game
+	BingosPerGame float64 `json:"bingosPerGame"`
+	// Fraction of the tiles of each letter (or "?" for blanks) still on a rack at the end of a game
+	StuckOnRack map[string]float64 `json:"stuckOnRack"`
+}
+
+// Plays games of Scrabble on the standard board between two players drawing from the tiles in tilesFile
+// (as written by TileSet) plus the number of blanks, who always play the highest scoring legal move of
+// the word source with the letter scores in scoresFile (as written by TileScores), exchanging their
+// whole rack (or passing, once the bag is too small) when they have no move. Saves a report of the
+// scores, bingos and which letters get stuck on racks as JSON
+func SimulateGames(ctx context.Context, tilesFile string, scoresFile string, wordsId sources.WordSourceId, games int, blanks int, seed int64) (*SelfPlayReport, error) {
+	if games < 1 {
+		return nil, fmt.Errorf("number of games must be positive")
+	}
+	tiles, err := ReadTiles(tilesFile)
+	if err != nil {
+		return nil, err
+	}
+	bag := []rune{}
+	for _, tile := range tileBag(tiles, blanks) {
+		if tile == anagram.Blank {
+			bag = append(bag, board.Blank)
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(tile)
+		if size != len(tile) {
+			return nil, fmt.Errorf("multi-letter tile %q cannot be played on a board", tile)
+		}
+		bag = append(bag, r)
+	}
+	if len(bag) < selfPlayPlayers*selfPlayRackSize {
+		return nil, fmt.Errorf("need at least %d tiles, have %d", selfPlayPlayers*selfPlayRackSize, len(bag))
+	}
+	contents, err := os.ReadFile(scoresFile)
+	if err != nil {
+		return nil, err
+	}
+	scores := map[string]int{}
+	if err := json.Unmarshal(contents, &scores); err != nil {
+		return nil, err
+	}
+	lex, err := lexicon.ForWordSource(ctx, wordsId)
+	if err != nil {
+		return nil, err
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	report := SelfPlayReport{Games: games, StuckOnRack: map[string]float64{}}
+	finalScores := []int{}
+	moves, bingos := 0, 0
+	stuck := map[rune]int{}
+	for i := range games {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		game := playGame(rng, bag, lex, scores)
+		finalScores = append(finalScores, game.scores...)
+		report.MeanTurns += float64(game.turns)
+		moves += game.moves
+		bingos += game.bingos
+		for _, rack := range game.racks {
+			for tile, count := range rack {
+				stuck[tile] += count
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Played game %d (scores: %v)\n", i+1, game.scores)
+	}
+
+	for _, score := range finalScores {
+		report.MeanScore += float64(score)
+	}
+	report.MeanScore /= float64(len(finalScores))
+	for _, score := range finalScores {
+		report.ScoreVariance += (float64(score) - report.MeanScore) * (float64(score) - report.MeanScore)
+	}
+	report.ScoreVariance /= float64(len(finalScores))
+	report.MeanTurns /= float64(games)
+	if moves > 0 {
+		report.BingoRate = float64(bingos) / float64(moves)
+	}
+	report.BingosPerGame = float64(bingos) / float64(games)
+	inBag := map[rune]int{}
+	for _, tile := range bag {
+		inBag[tile]++
+	}
+	for tile, count := range inBag {
+		report.StuckOnRack[string(tile)] = float64(stuck[tile]) / float64(count*games)
+	}
+
+	name := fmt.Sprintf("%s-%s-%s-b%d",
+		strings.TrimSuffix(path.Base(tilesFile), path.Ext(tilesFile)),
+		strings.TrimSuffix(path.Base(scoresFile), path.Ext(scoresFile)), wordsId, blanks)
+	outputFile, err := utils.SelfPlayFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return &report, writeJson(outputFile, report)
+}
+
+// Outcome of a single self-play game
+type selfPlayGame struct {
+	// final score of each player
+	scores []int
+	// tiles left on each player's rack at the end of the game
+	racks []board.Rack
+	// number of turns taken, and of them the number of moves and bingos played
+	turns  int
+	moves  int
+	bingos int
+}
+
+// Plays a game between greedy players from a shuffled copy of the bag
+func playGame(rng *rand.Rand, tiles []rune, lex *lexicon.Lexicon, scores map[string]int) selfPlayGame {
+	bag := make([]rune, len(tiles))
+	for i, j := range rng.Perm(len(tiles)) {
+		bag[i] = tiles[j]
+	}
+	draw := func(rack board.Rack) {
+		for rack.Size() < selfPlayRackSize && len(bag) > 0 {
+			rack[bag[len(bag)-1]]++
+			bag = bag[:len(bag)-1]
+		}
+	}
+
+	b := board.Standard()
+	game := selfPlayGame{scores: make([]int, selfPlayPlayers)}
+	for range selfPlayPlayers {
+		rack := board.Rack{}
+		draw(rack)
+		game.racks = append(game.racks, rack)
+	}
+	scoreless := 0
+	for player := 0; scoreless < selfPlayScorelessTurns; player = (player + 1) % selfPlayPlayers {
+		rack := game.racks[player]
+		game.turns++
+		moves := board.Moves(b, rack, lex, scores)
+		if len(moves) == 0 {
+			if len(bag) >= selfPlayRackSize {
+				// exchange the whole rack
+				returned := rack.Tiles()
+				clear(rack)
+				draw(rack)
+				bag = append(bag, returned...)
+				rng.Shuffle(len(bag), func(i, j int) { bag[i], bag[j] = bag[j], bag[i] })
+			}
+			scoreless++
+			continue
+		}
+
+		move := moves[0]
+		b.Play(move)
+		rack.Remove(move)
+		draw(rack)
+		game.scores[player] += move.Score
+		game.moves++
+		if move.Bingo() {
+			game.bingos++
+		}
+		if move.Score > 0 {
+			scoreless = 0
+		} else {
+			scoreless++
+		}
+		if rack.Size() == 0 {
+			// going out scores the tiles left on every other rack
+			for other, otherRack := range game.racks {
+				for tile, count := range otherRack {
+					value := 0
+					if tile != board.Blank {
+						value = scores[string(tile)] * count
+					}
+					game.scores[other] -= value
+					game.scores[player] += value
+				}
+			}
+			break
+		}
+	}
+	return game
+}
+
+func init() {
+	utils.RegisterCommand(&utils.Command{
+		Name:    "self-play",
+		Args:    []string{"tiles file"},
+		Summary: "Play games of Scrabble to evaluate tiles and scores",
+		Description: `Plays games of Scrabble on the standard board between two players drawing from the tiles
+JSON file plus an optional number of blanks, who always play the highest scoring move of the
+word source with the letter scores of the scores JSON file, storing the mean and variance of
+their scores, the bingo rate, and the fraction of each letter left stuck on a rack at the end
+of a game as a JSON file in the data directory`,
+		Setup: func(flags *flag.FlagSet) func(ctx context.Context, args []string) error {
+			scoresFile := flags.String("scores", "", "Scores JSON file of the letters (required)")
+			words := flags.String("words", "", "Word source of words which can be played (required)")
+			games := flags.Int("games", 100, "Number of games to play")
+			blanks := flags.Int("blanks", 0, "Number of blank tiles to add to the tiles")
+			seed := flags.Int64("seed", 1, "Seed for drawing tiles")
+			return func(ctx context.Context, args []string) error {
+				if *scoresFile == "" || *words == "" {
+					return fmt.Errorf("the --scores and --words flags are required")
+				}
+				report, err := SimulateGames(ctx, args[0], *scoresFile, sources.WordSourceId(*words), *games, *blanks, *seed)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Mean score per game: %.1f (variance %.1f), bingo rate: %.2f%%\n",
+					report.MeanScore, report.ScoreVariance, report.BingoRate*100)
+				return nil
+			}
+		},
+	})
+}
@@ -0,0 +1,99 @@
+package processes
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/digitaltembo/motli/packages/corpus/sources"
+	"github.com/digitaltembo/motli/packages/corpus/spellingbee"
+	"github.com/digitaltembo/motli/packages/corpus/utils"
+)
+
+// A generated Spelling Bee puzzle, along with how common its answers are
+type SpellingBeePuzzle struct {
+	spellingbee.Puzzle
+	// Median Freq of the answers in the language
+	Commonness int `json:"commonness"`
+}
+
+// Generates Spelling Bee puzzles from the reasonable lower-case words of the word source which are used
+// in the language source, keeping puzzles with between minAnswers and maxAnswers answers and whose
+// commonness (the median usage of their answers) is between minFreq and maxFreq, where a non-positive
+// maximum is unbounded. Saves the puzzles, most common first, as JSON
+func SpellingBee(ctx context.Context, languageId sources.LanguageSourceId, wordsId sources.WordSourceId, minAnswers int, maxAnswers int, minFreq int, maxFreq int) ([]SpellingBeePuzzle, error) {
+	ws, err := FrequencyWordSource(ctx, languageId, wordsId)
+	if err != nil {
+		return nil, err
+	}
+	freqs := map[string]int{}
+	words := []string{}
+	for _, word := range ws.GetWordList() {
+		if word.Freq > 0 && word.Word == strings.ToLower(word.Word) && sources.ReasonableEnglishWord(word) {
+			freqs[word.Word] = word.Freq
+			words = append(words, word.Word)
+		}
+	}
+
+	puzzles := []SpellingBeePuzzle{}
+	for _, puzzle := range spellingbee.Generate(words) {
+		if len(puzzle.Answers) < minAnswers || (maxAnswers > 0 && len(puzzle.Answers) > maxAnswers) {
+			continue
+		}
+		answerFreqs := []int{}
+		for _, answer := range puzzle.Answers {
+			answerFreqs = append(answerFreqs, freqs[answer])
+		}
+		slices.Sort(answerFreqs)
+		commonness := answerFreqs[len(answerFreqs)/2]
+		if commonness < minFreq || (maxFreq > 0 && commonness > maxFreq) {
+			continue
+		}
+		puzzles = append(puzzles, SpellingBeePuzzle{Puzzle: puzzle, Commonness: commonness})
+	}
+	slices.SortStableFunc(puzzles, func(a, b SpellingBeePuzzle) int {
+		return cmp.Compare(b.Commonness, a.Commonness)
+	})
+
+	outputFile, err := utils.SpellingBeeFile(fmt.Sprintf("%s-%s-a%d-%d-f%d-%d",
+		languageId, wordsId, minAnswers, maxAnswers, minFreq, maxFreq))
+	if err != nil {
+		return nil, err
+	}
+	return puzzles, writeJson(outputFile, puzzles)
+}
+
+func init() {
+	utils.RegisterCommand(&utils.Command{
+		Name:    "spelling-bee",
+		Args:    []string{"language"},
+		Summary: "Generate Spelling Bee puzzles",
+		Description: `Generates Spelling Bee puzzles from every set of seven letters of a word of the word source,
+with each choice of center letter, whose answers are the words of at least four letters used
+in the language. Puzzles are scored (1 point for four letters, a point per letter otherwise,
+and 7 more for a pangram) and kept if they have between min-answers and max-answers answers,
+and if the median usage of their answers is between min-freq and max-freq, storing them as a
+JSON file in the data directory`,
+		Setup: func(flags *flag.FlagSet) func(ctx context.Context, args []string) error {
+			words := flags.String("words", "", "Word source of answers (required)")
+			minAnswers := flags.Int("min-answers", 20, "Minimum number of answers of a puzzle")
+			maxAnswers := flags.Int("max-answers", 80, "Maximum number of answers of a puzzle")
+			minFreq := flags.Int("min-freq", 0, "Minimum median usage of the answers of a puzzle")
+			maxFreq := flags.Int("max-freq", 0, "Maximum median usage of the answers of a puzzle")
+			return func(ctx context.Context, args []string) error {
+				if *words == "" {
+					return fmt.Errorf("the --words flag is required")
+				}
+				puzzles, err := SpellingBee(ctx, sources.LanguageSourceId(args[0]), sources.WordSourceId(*words), *minAnswers, *maxAnswers, *minFreq, *maxFreq)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Generated %d spelling bee puzzles\n", len(puzzles))
+				return nil
+			}
+		},
+	})
+}
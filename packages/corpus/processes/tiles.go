@@ -1,10 +1,16 @@
 package processes
 
 import (
+	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"maps"
 	"math"
 	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/digitaltembo/motli/packages/corpus/sources"
 	"github.com/digitaltembo/motli/packages/corpus/utils"
@@ -15,29 +21,142 @@ import (
 // distribution of tiles - currently specifically by looking at the number of
 // occurrences of a given character throughout the entire corpus of example sentences
 // in the wiktionary for the provided language
-func TileSet(language sources.LanguageSourceId, tileCount int) (map[string]int, error) {
-	analysis, err := AnalyzeNgrams(language, 1)
+func TileSet(ctx context.Context, language sources.LanguageSourceId, tileCount int) (map[string]int, error) {
+	analysis, err := AnalyzeNgrams(ctx, AnalysisMode_Usage, language, "", NgramRange{Min: 1, Max: 1}, 0, 0)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Printf("Hey so found %d\n", len(analysis))
-	// search for a bucket size that gives the appropriate number of tiles
-	min := 1000000
-	max := 0
+	counts := map[string]int{}
 	for _, a := range analysis {
-		fmt.Fprintf(os.Stderr, "%s - %d\n", a.Symbol, a.CorpusCounts.Count)
-		if a.CorpusCounts.Count > max {
-			max = a.CorpusCounts.Count
+		counts[a.Symbol] = a.CorpusCounts.Count
+	}
+	tileMap := distributeTiles(counts, tileCount)
+	outputFile, err := utils.TileFile(string(language), tileCount)
+	if err != nil {
+		return nil, err
+	}
+	return tileMap, writeJson(outputFile, tileMap)
+}
+
+// Creates a distribution of tiles like TileSet, but including multi-letter tiles (e.g. "qu", "th", "ing"),
+// either the provided candidates or, if there are none, the topK ngrams of 2 to 4 letters which cover
+// the most letters of the corpus. Each occurrence of a multi-letter tile is discounted from the counts of
+// the letters (and shorter multi-letter tiles) it consumes, so that they are not double-counted
+func MultiTileSet(ctx context.Context, language sources.LanguageSourceId, tileCount int, candidates []string, topK int) (map[string]int, error) {
+	maxLength := 4
+	if len(candidates) > 0 {
+		maxLength = 1
+		candidates = slices.Clone(candidates)
+		for i, candidate := range candidates {
+			// the analysis is of lowercased words
+			candidate = strings.ToLower(candidate)
+			candidates[i] = candidate
+			if utf8.RuneCountInString(candidate) < 2 {
+				return nil, fmt.Errorf("multi-letter tile %q must have at least 2 letters", candidate)
+			}
+			maxLength = max(maxLength, utf8.RuneCountInString(candidate))
+		}
+	}
+	analysis, err := AnalyzeNgrams(ctx, AnalysisMode_Usage, language, "", NgramRange{Min: 1, Max: maxLength}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	for _, a := range analysis {
+		counts[a.Symbol] = a.CorpusCounts.Count
+	}
+
+	if len(candidates) == 0 {
+		candidates = topCoverageNgrams(analysis, topK)
+	}
+	for _, candidate := range candidates {
+		if _, ok := counts[candidate]; !ok {
+			return nil, fmt.Errorf("multi-letter tile %q is not made of letters of the language", candidate)
+		}
+	}
+	tileMap := distributeTiles(discountMultiTiles(counts, candidates), tileCount)
+
+	outputFile, err := utils.TileFile(fmt.Sprintf("%s-multi", language), tileCount)
+	if err != nil {
+		return nil, err
+	}
+	return tileMap, writeJson(outputFile, tileMap)
+}
+
+// The k multi-letter ngrams which save the most letters, scoring each by the number of
+// words it occurs in times the number of extra letters it covers
+func topCoverageNgrams(analysis []*Analysis, k int) []string {
+	multi := []*Analysis{}
+	for _, a := range analysis {
+		if utf8.RuneCountInString(a.Symbol) > 1 {
+			multi = append(multi, a)
 		}
-		if a.CorpusCounts.Count < min {
-			min = a.CorpusCounts.Count
+	}
+	coverage := func(a *Analysis) int {
+		return a.CorpusCounts.Count * (utf8.RuneCountInString(a.Symbol) - 1)
+	}
+	slices.SortStableFunc(multi, func(a, b *Analysis) int {
+		return coverage(b) - coverage(a)
+	})
+	top := []string{}
+	for _, a := range multi[:min(k, len(multi))] {
+		top = append(top, a.Symbol)
+	}
+	return top
+}
+
+// Counts of the single letters and multi-letter tiles, where the count of each tile is reduced
+// by the counts of the (longer) multi-letter tiles that contain it
+func discountMultiTiles(counts map[string]int, multiTiles []string) map[string]int {
+	// discount longer tiles first, so each tile's count is already final when it is subtracted
+	byLength := slices.Clone(multiTiles)
+	slices.SortStableFunc(byLength, func(a, b string) int {
+		return utf8.RuneCountInString(b) - utf8.RuneCountInString(a)
+	})
+
+	discounted := map[string]int{}
+	for symbol, count := range counts {
+		if utf8.RuneCountInString(symbol) == 1 {
+			discounted[symbol] = count
 		}
 	}
+	for _, tile := range byLength {
+		discounted[tile] = counts[tile]
+	}
+	for i, tile := range byLength {
+		for _, shorter := range byLength[i+1:] {
+			if n := strings.Count(tile, shorter); n > 0 && utf8.RuneCountInString(shorter) < utf8.RuneCountInString(tile) {
+				discounted[shorter] -= n * discounted[tile]
+			}
+		}
+		for _, letter := range tile {
+			discounted[string(letter)] -= discounted[tile]
+		}
+	}
+	for symbol, count := range discounted {
+		discounted[symbol] = max(count, 0)
+	}
+	return discounted
+}
+
+// Search for a bucket size that gives the appropriate number of tiles when making one tile
+// for every bucket size occurrences of each symbol
+func distributeTiles(counts map[string]int, tileCount int) map[string]int {
+	if len(counts) == 0 {
+		return map[string]int{}
+	}
+	values := slices.Collect(maps.Values(counts))
+	min := slices.Min(values)
+	max := slices.Max(values)
 	tiles := -1
 	var tileMap map[string]int
 	for tiles != tileCount {
 		bucketSize := (max-min)/2 + min
-		tileMap, tiles = tilesGivenBucketSize(analysis, bucketSize)
+		// all-zero counts would otherwise make the bucket size 0
+		if bucketSize < 1 {
+			bucketSize = 1
+		}
+		tileMap, tiles = tilesGivenBucketSize(counts, bucketSize)
 		if tiles < tileCount {
 			max = bucketSize
 		} else {
@@ -45,35 +164,67 @@ func TileSet(language sources.LanguageSourceId, tileCount int) (map[string]int,
 		}
 
 		if max-min < 2 {
-			fmt.Fprintf(os.Stderr, "Could not match file size, found %d with a bucket size of %d", tiles, bucketSize)
+			fmt.Fprintf(os.Stderr, "Could not match file size, found %d with a bucket size of %d\n", tiles, bucketSize)
 			break
 		}
 	}
-	outputFile, err := utils.TileFile(string(language), tileCount)
-	if err != nil {
-		return nil, err
-	}
-	asJson, err := json.MarshalIndent(tileMap, "", "  ")
+	return tileMap
+}
+
+// Saves the value as indented JSON in the output file
+func writeJson(outputFile string, value any) error {
+	asJson, err := json.MarshalIndent(value, "", "  ")
 	if err != nil {
-		return nil, err
+		return err
 	}
 	out, err := os.Create(outputFile)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer out.Close()
-	fmt.Fprint(out, string(asJson))
-	return tileMap, nil
+	_, err = fmt.Fprint(out, string(asJson))
+	return err
 }
 
 // Count the tiles created by making one tile for every bucketsize appearances of the tile string
 // throughout the entire corpus
-func tilesGivenBucketSize(analysis []*Analysis, bucketSize int) (map[string]int, int) {
+func tilesGivenBucketSize(counts map[string]int, bucketSize int) (map[string]int, int) {
 	tileMap := map[string]int{}
 	count := 0
-	for _, tile := range analysis {
-		tileMap[tile.Symbol] = int(math.Ceil(float64(tile.CorpusCounts.Count) / float64(bucketSize)))
-		count += tileMap[tile.Symbol]
+	for symbol, symbolCount := range counts {
+		tileMap[symbol] = int(math.Ceil(float64(symbolCount) / float64(bucketSize)))
+		count += tileMap[symbol]
 	}
 	return tileMap, count
 }
+
+func init() {
+	utils.RegisterCommand(&utils.Command{
+		Name:    "tiles",
+		Args:    []string{"language"},
+		Summary: "Create a set of tiles following the letter usage of a language",
+		Description: `Runs analysis of ngram size of 1 and creates a set of tiles of the provided size whose
+frequency corresponds to the frequency of the ngrams in that language's corpus, storing the
+results as a JSON file in the data directory. Multi-letter tiles can be included, either the
+provided comma-separated list (e.g. "qu,th,ing") or the top most useful digraphs/trigraphs/
+tetragraphs, discounting the letters they consume from the single-letter tiles`,
+		Setup: func(flags *flag.FlagSet) func(ctx context.Context, args []string) error {
+			count := flags.Int("count", 100, "Number of tiles in the set")
+			multi := flags.String("multi", "", "Comma-separated multi-letter tiles to include in the set")
+			multiTop := flags.Int("multi-top", 0, "Automatically include this many of the most useful multi-letter tiles")
+			return func(ctx context.Context, args []string) error {
+				language := sources.LanguageSourceId(args[0])
+				if *multi == "" && *multiTop == 0 {
+					_, err := TileSet(ctx, language, *count)
+					return err
+				}
+				candidates := []string{}
+				if *multi != "" {
+					candidates = strings.Split(*multi, ",")
+				}
+				_, err := MultiTileSet(ctx, language, *count, candidates, *multiTop)
+				return err
+			}
+		},
+	})
+}
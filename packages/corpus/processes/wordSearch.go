@@ -0,0 +1,113 @@
+package processes
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/digitaltembo/motli/packages/corpus/lexicon"
+	"github.com/digitaltembo/motli/packages/corpus/sources"
+	"github.com/digitaltembo/motli/packages/corpus/utils"
+	"github.com/digitaltembo/motli/packages/corpus/wordsearch"
+)
+
+// Number of tiles in the set of tiles whose distribution fills word search grids
+const wordSearchFillerTiles = 100
+
+// Generates a width by height word search hiding the words of the theme, or if there is no theme a
+// random selection of count reasonable lower-case words of the word source which fit in the grid, along
+// the directions (a preset like "medium" or a list like "right,down"). The rest of the grid is filled
+// following the distribution of a TileSet of the language, avoiding extra words of the word source.
+// Saves the puzzle as JSON
+func WordSearch(ctx context.Context, language sources.LanguageSourceId, wordsId sources.WordSourceId, theme []string, count int, width int, height int, directions string, seed int64) (*wordsearch.Puzzle, error) {
+	parsedDirections, err := wordsearch.ParseDirections(directions)
+	if err != nil {
+		return nil, err
+	}
+	filler, err := TileSet(ctx, language, wordSearchFillerTiles)
+	if err != nil {
+		return nil, err
+	}
+	lex, err := lexicon.ForWordSource(ctx, wordsId)
+	if err != nil {
+		return nil, err
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	words := theme
+	name := fmt.Sprintf("%s-%s-%dx%d-%s-%d", language, wordsId, width, height, directions, seed)
+	if len(words) == 0 {
+		ws, err := sources.GetWordSource(ctx, wordsId)
+		if err != nil {
+			return nil, err
+		}
+		candidates := []string{}
+		for _, word := range ws.GetWordList() {
+			length := utf8.RuneCountInString(word.Word)
+			if length >= 3 && length <= max(width, height) &&
+				word.Word == strings.ToLower(word.Word) && sources.ReasonableEnglishWord(word) {
+				candidates = append(candidates, word.Word)
+			}
+		}
+		// word sources list their words in no particular order
+		slices.Sort(candidates)
+		for _, i := range rng.Perm(len(candidates))[:min(count, len(candidates))] {
+			words = append(words, candidates[i])
+		}
+	} else {
+		name = fmt.Sprintf("%s-%s", strings.Join(theme, "_"), name)
+	}
+
+	puzzle, err := wordsearch.Generate(rng, words, width, height, parsedDirections, filler, lex)
+	if err != nil {
+		return nil, err
+	}
+	outputFile, err := utils.WordSearchFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return puzzle, writeJson(outputFile, puzzle)
+}
+
+func init() {
+	utils.RegisterCommand(&utils.Command{
+		Name:    "word-search",
+		Args:    []string{"language"},
+		Summary: "Generate a word search",
+		Description: `Generates a word search hiding either the comma-separated words of the theme or random words
+of the word source, crossing where their letters agree, along the directions: "easy" (right
+and down), "medium" (also diagonally), "hard" (also backwards) or a list like
+"right,down,down-right". The rest of the grid is filled following a set of tiles of the
+language, redrawn wherever extra words of the word source appear, storing the puzzle as a JSON
+file in the data directory`,
+		Setup: func(flags *flag.FlagSet) func(ctx context.Context, args []string) error {
+			words := flags.String("words", "", "Word source of hidden words and of the extra words to avoid (required)")
+			theme := flags.String("theme", "", "Comma-separated words to hide")
+			count := flags.Int("count", 12, "Number of random words to hide when there is no theme")
+			width := flags.Int("width", 12, "Number of columns of the grid")
+			height := flags.Int("height", 12, "Number of rows of the grid")
+			directions := flags.String("directions", "medium", "Directions words can be hidden in: easy, medium, hard, or a comma-separated list like right,down")
+			seed := flags.Int64("seed", 1, "Seed for placing words and filling the grid")
+			return func(ctx context.Context, args []string) error {
+				if *words == "" {
+					return fmt.Errorf("the --words flag is required")
+				}
+				themeWords := []string{}
+				if *theme != "" {
+					themeWords = strings.Split(*theme, ",")
+				}
+				puzzle, err := WordSearch(ctx, sources.LanguageSourceId(args[0]), sources.WordSourceId(*words), themeWords, *count, *width, *height, *directions, *seed)
+				if err != nil {
+					return err
+				}
+				fmt.Print(puzzle)
+				fmt.Printf("Hid %d words, skipped %d, with %d extra words\n", len(puzzle.Words), len(puzzle.Skipped), len(puzzle.Accidental))
+				return nil
+			}
+		},
+	})
+}
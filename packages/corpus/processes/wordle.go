@@ -0,0 +1,188 @@
+package processes
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/digitaltembo/motli/packages/corpus/sources"
+	"github.com/digitaltembo/motli/packages/corpus/utils"
+)
+
+// How hard a Wordle answer is expected to be, based on how common it is
+type WordleTier string
+
+const (
+	// The most common third of answers
+	WordleTier_Easy = "easy"
+	// The middle third of answers
+	WordleTier_Medium = "medium"
+	// The least common third of answers
+	WordleTier_Hard = "hard"
+)
+
+// Format of the dates in a Wordle schedule
+const WordleDateFormat = "2006-01-02"
+
+// Parts of speech (as categorized by wiktionary) that never make good Wordle answers
+var wordleExcludedCategories = []string{"name", "abbrev", "prefix", "suffix", "infix", "affix", "symbol", "character", "punct", "phrase", "contraction"}
+
+// Answer and allowed-guess lists for a game of Wordle, along with a schedule of daily answers
+type WordleLists struct {
+	// Number of letters of each word
+	Length int `json:"length"`
+	// Answers, from most to least common
+	Answers []WordleAnswer `json:"answers"`
+	// Every word allowed as a guess, including the answers, alphabetically
+	Guesses []string `json:"guesses"`
+	// One answer per day, starting from the start date, going through every answer once
+	Schedule []WordleDay `json:"schedule"`
+}
+
+// An answer of a game of Wordle
+type WordleAnswer struct {
+	Word string     `json:"word"`
+	Freq int        `json:"freq"`
+	Tier WordleTier `json:"tier"`
+}
+
+// The answer of a game of Wordle on a day
+type WordleDay struct {
+	Date string     `json:"date"`
+	Word string     `json:"word"`
+	Tier WordleTier `json:"tier"`
+}
+
+// Derives the Wordle guess and answer lists of words of the given length from the word source. Guesses
+// are every reasonable lower-case word; answers are the guesses used in the language source, leaving out
+// plurals and words that are only names, abbreviations, affixes or symbols, ranked by frequency of usage
+// and limited to the most common maxAnswers (if positive), and split into thirds of difficulty tiers.
+// The schedule is a seeded shuffle of the answers starting from the start date. Saves the lists as JSON
+func Wordle(ctx context.Context, languageId sources.LanguageSourceId, wordsId sources.WordSourceId, length int, maxAnswers int, start time.Time, seed int64) (*WordleLists, error) {
+	if length < 1 {
+		return nil, fmt.Errorf("word length must be positive")
+	}
+	ws, err := FrequencyWordSource(ctx, languageId, wordsId)
+	if err != nil {
+		return nil, err
+	}
+
+	lists := WordleLists{Length: length, Answers: []WordleAnswer{}, Guesses: []string{}, Schedule: []WordleDay{}}
+	for _, word := range ws.GetWordList() {
+		if !wordleGuess(word, length) {
+			continue
+		}
+		lists.Guesses = append(lists.Guesses, word.Word)
+		if word.Freq > 0 && wordleAnswer(ws, word) {
+			lists.Answers = append(lists.Answers, WordleAnswer{Word: word.Word, Freq: word.Freq})
+		}
+	}
+	slices.Sort(lists.Guesses)
+	lists.Guesses = slices.Compact(lists.Guesses)
+	slices.SortFunc(lists.Answers, func(a, b WordleAnswer) int {
+		if c := cmp.Compare(b.Freq, a.Freq); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Word, b.Word)
+	})
+	lists.Answers = slices.CompactFunc(lists.Answers, func(a, b WordleAnswer) bool { return a.Word == b.Word })
+	if maxAnswers > 0 && len(lists.Answers) > maxAnswers {
+		lists.Answers = lists.Answers[:maxAnswers]
+	}
+	for i := range lists.Answers {
+		switch {
+		case i*3 < len(lists.Answers):
+			lists.Answers[i].Tier = WordleTier_Easy
+		case i*3 < 2*len(lists.Answers):
+			lists.Answers[i].Tier = WordleTier_Medium
+		default:
+			lists.Answers[i].Tier = WordleTier_Hard
+		}
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	for day, i := range rng.Perm(len(lists.Answers)) {
+		lists.Schedule = append(lists.Schedule, WordleDay{
+			Date: start.AddDate(0, 0, day).Format(WordleDateFormat),
+			Word: lists.Answers[i].Word,
+			Tier: lists.Answers[i].Tier,
+		})
+	}
+
+	outputFile, err := utils.WordleFile(fmt.Sprintf("%s-%s-%d", languageId, wordsId, length))
+	if err != nil {
+		return nil, err
+	}
+	return &lists, writeJson(outputFile, lists)
+}
+
+// Whether the word is a reasonable lower-case word of the length, excluding capitalized proper nouns
+func wordleGuess(word *sources.Word, length int) bool {
+	return utf8.RuneCountInString(word.Word) == length &&
+		word.Word == strings.ToLower(word.Word) &&
+		sources.ReasonableEnglishWord(word)
+}
+
+// Whether the word makes a fair answer: it has some part of speech other than the excluded ones (if the
+// word source categorizes its words) and does not look like the plural of another word of the source
+func wordleAnswer(ws sources.WordSource, word *sources.Word) bool {
+	if len(word.Categories) > 0 && !slices.ContainsFunc(word.Categories, func(cat int) bool {
+		return !slices.Contains(wordleExcludedCategories, ws.GetCategory(cat))
+	}) {
+		return false
+	}
+	return !looksPlural(ws, word.Word)
+}
+
+// Whether the word ends in an "s" or "es" that can be removed to give another word of the source,
+// e.g. "cats" or "boxes" (but not "glass" or "hiss")
+func looksPlural(ws sources.WordSource, word string) bool {
+	if !strings.HasSuffix(word, "s") || strings.HasSuffix(word, "ss") {
+		return false
+	}
+	if ws.GetWord(strings.TrimSuffix(word, "s")) != nil {
+		return true
+	}
+	return strings.HasSuffix(word, "es") && ws.GetWord(strings.TrimSuffix(word, "es")) != nil
+}
+
+func init() {
+	utils.RegisterCommand(&utils.Command{
+		Name:    "wordle",
+		Args:    []string{"language"},
+		Summary: "Generate Wordle answer and guess lists",
+		Description: `Generates Wordle lists of words from the word source: every reasonable lower-case word as an
+allowed guess, and as answers the words used in the language, without plurals, names or
+abbreviations, ranked by usage into easy, medium and hard thirds. Also schedules one answer
+per day in a seeded shuffle from the start date, storing the lists and schedule as a JSON file
+in the data directory`,
+		Setup: func(flags *flag.FlagSet) func(ctx context.Context, args []string) error {
+			words := flags.String("words", "", "Word source of guesses and answers (required)")
+			length := flags.Int("length", 5, "Number of letters of each word")
+			answers := flags.Int("answers", 0, "Maximum number of answers, keeping the most common")
+			start := flags.String("start", "2021-06-19", "First day of the schedule of answers")
+			seed := flags.Int64("seed", 1, "Seed for shuffling the schedule")
+			return func(ctx context.Context, args []string) error {
+				if *words == "" {
+					return fmt.Errorf("the --words flag is required")
+				}
+				startDate, err := time.Parse(WordleDateFormat, *start)
+				if err != nil {
+					return err
+				}
+				lists, err := Wordle(ctx, sources.LanguageSourceId(args[0]), sources.WordSourceId(*words), *length, *answers, startDate, *seed)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Generated %d answers and %d guesses\n", len(lists.Answers), len(lists.Guesses))
+				return nil
+			}
+		},
+	})
+}
@@ -0,0 +1,165 @@
+package processes
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/digitaltembo/motli/packages/corpus/sources"
+	"github.com/digitaltembo/motli/packages/corpus/utils"
+	"github.com/digitaltembo/motli/packages/corpus/wordle"
+)
+
+// Number of guesses allowed in a game of Wordle
+const WordleGuesses = 6
+
+// How hard the answers of a game of Wordle are for an entropy-maximizing solver
+type WordleDifficulty struct {
+	// Number of letters of each word
+	Length int `json:"length"`
+	// Number of allowed guesses
+	Guesses int `json:"guesses"`
+	// Best opening guesses, from best to worst
+	Openers []wordle.Opener `json:"openers"`
+	// Average number of guesses to solve an answer
+	MeanGuesses float64 `json:"meanGuesses"`
+	// Number of answers solved in each number of guesses
+	Distribution map[string]int `json:"distribution"`
+	// Fraction of answers taking more than the 6 allowed guesses
+	Failures float64 `json:"failures"`
+	// Every answer, from hardest to easiest
+	Answers []wordle.Game `json:"answers"`
+}
+
+// Plays every answer with the entropy-maximizing wordle.Solver, guessing from the reasonable lower-case
+// words of the length in the word source. Answers are read from answersFile, either a word list or the
+// JSON lists written by Wordle, or if empty are every allowed guess. Saves how many guesses each answer
+// took, the distribution of guesses, and the top openers (by expected information) as JSON
+func SolveWordle(ctx context.Context, wordsId sources.WordSourceId, answersFile string, length int, openers int) (*WordleDifficulty, error) {
+	ws, err := sources.GetWordSource(ctx, wordsId)
+	if err != nil {
+		return nil, err
+	}
+	guesses := []string{}
+	for _, word := range ws.GetWordList() {
+		if wordleGuess(word, length) {
+			guesses = append(guesses, word.Word)
+		}
+	}
+	answers := guesses
+	name := fmt.Sprintf("%s-%d", wordsId, length)
+	if answersFile != "" {
+		if answers, err = readWordleAnswers(answersFile, length); err != nil {
+			return nil, err
+		}
+		name = fmt.Sprintf("%s-%s", strings.TrimSuffix(path.Base(answersFile), path.Ext(answersFile)), name)
+	}
+	solver, err := wordle.NewSolver(guesses, answers)
+	if err != nil {
+		return nil, err
+	}
+
+	difficulty := WordleDifficulty{
+		Length:       length,
+		Guesses:      len(guesses),
+		Openers:      solver.Openers(openers),
+		Distribution: map[string]int{},
+	}
+	failures := 0
+	for i, answer := range solver.Answers() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		game, err := solver.Play(answer)
+		if err != nil {
+			return nil, err
+		}
+		difficulty.Answers = append(difficulty.Answers, *game)
+		difficulty.MeanGuesses += float64(len(game.Guesses))
+		difficulty.Distribution[strconv.Itoa(len(game.Guesses))]++
+		if len(game.Guesses) > WordleGuesses {
+			failures++
+		}
+		if (i+1)%100 == 0 {
+			fmt.Fprintf(os.Stderr, "Solved %d answers (latest: %s in %d)\n", i+1, answer, len(game.Guesses))
+		}
+	}
+	difficulty.MeanGuesses /= float64(len(difficulty.Answers))
+	difficulty.Failures = float64(failures) / float64(len(difficulty.Answers))
+	slices.SortStableFunc(difficulty.Answers, func(a, b wordle.Game) int {
+		return cmp.Compare(len(b.Guesses), len(a.Guesses))
+	})
+
+	outputFile, err := utils.WordleDifficultyFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return &difficulty, writeJson(outputFile, difficulty)
+}
+
+// Reads the answers of the length from the JSON lists written by Wordle, or from a word list
+func readWordleAnswers(answersFile string, length int) ([]string, error) {
+	answers := []string{}
+	if path.Ext(answersFile) == ".json" {
+		contents, err := os.ReadFile(answersFile)
+		if err != nil {
+			return nil, err
+		}
+		lists := WordleLists{}
+		if err := json.Unmarshal(contents, &lists); err != nil {
+			return nil, err
+		}
+		for _, answer := range lists.Answers {
+			answers = append(answers, answer.Word)
+		}
+	} else {
+		ws, err := sources.NewWordListWordSource(answersFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, word := range ws.GetWordList() {
+			if wordleGuess(word, length) {
+				answers = append(answers, word.Word)
+			}
+		}
+	}
+	if len(answers) == 0 {
+		return nil, fmt.Errorf("no answers of %d letters in %s", length, answersFile)
+	}
+	return answers, nil
+}
+
+func init() {
+	utils.RegisterCommand(&utils.Command{
+		Name:    "wordle solve",
+		Args:    []string{"words"},
+		Summary: "Measure how many guesses each Wordle answer takes",
+		Description: `Plays every Wordle answer (from a word list or "wordle" JSON file, or by default every word
+of the length) with a solver always making the guess from the word source that gives the most
+information, storing the guesses each answer took, the distribution of guesses, and the best
+opening guesses as a JSON file in the data directory`,
+		Setup: func(flags *flag.FlagSet) func(ctx context.Context, args []string) error {
+			answersFile := flags.String("answers", "", "Word list or wordle lists JSON file of answers to solve")
+			length := flags.Int("length", 5, "Number of letters of each word")
+			openers := flags.Int("openers", 10, "Number of best opening guesses to report")
+			return func(ctx context.Context, args []string) error {
+				difficulty, err := SolveWordle(ctx, sources.WordSourceId(args[0]), *answersFile, *length, *openers)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Mean guesses per answer: %.3f, failures: %.2f%%\n", difficulty.MeanGuesses, difficulty.Failures*100)
+				for _, opener := range difficulty.Openers {
+					fmt.Printf("%s %.3f\n", opener.Word, opener.Entropy)
+				}
+				return nil
+			}
+		},
+	})
+}
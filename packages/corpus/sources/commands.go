@@ -0,0 +1,94 @@
+package sources
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+
+	"github.com/digitaltembo/motli/packages/corpus/utils"
+)
+
+// Every supported language source
+var LanguageSourceIds = []LanguageSourceId{
+	LanguageSourceId_SimpleEn,
+	LanguageSourceId_SimpleEnAll,
+	LanguageSourceId_SimpleEnFromEnExamples,
+	LanguageSourceId_En,
+	LanguageSourceId_EnAll,
+	LanguageSourceId_EnCsw21,
+	LanguageSourceId_EnNswl2023,
+	LanguageSourceId_WpSimpleEn,
+	LanguageSourceId_WpSimpleEnAll,
+	LanguageSourceId_WpEn,
+	LanguageSourceId_WpEnAll,
+}
+
+// Every supported word source, besides word list files loaded with WordSourceIdPrefix_File
+var WordSourceIds = []WordSourceId{
+	WordSourceId_WeSimpleEnAll,
+	WordSourceId_WeEnAll,
+	WordSourceId_Csw21,
+	WordSourceId_Nswl2023,
+}
+
+// Every file that can be downloaded with Download
+func Downloads() []string {
+	downloads := []string{}
+	for language := range wikiextractFiles {
+		downloads = append(downloads, string(language))
+	}
+	for language := range wikipediaFiles {
+		downloads = append(downloads, string(language))
+	}
+	for list := range wordListFiles {
+		downloads = append(downloads, string(list))
+	}
+	slices.Sort(downloads)
+	return downloads
+}
+
+func init() {
+	utils.RegisterCommand(&utils.Command{
+		Name:    "download",
+		Args:    []string{"source"},
+		Summary: "Download a wiktionary extract, wikipedia dump or word list",
+		Description: `Downloads the wikiextract file for the given language, the wikipedia dumps "wp-en" and
+"wp-simple-en", or the word lists "csw21" and "nswl2023", storing them in the data directory.
+See "corpus sources list" for every file that can be downloaded`,
+		Setup: func(flags *flag.FlagSet) func(ctx context.Context, args []string) error {
+			return func(ctx context.Context, args []string) error {
+				filename, err := Download(ctx, args[0])
+				if err != nil {
+					return err
+				}
+				fmt.Printf("File downloaded at %s\n", filename)
+				return nil
+			}
+		},
+	})
+
+	utils.RegisterCommand(&utils.Command{
+		Name:        "sources list",
+		Summary:     "List the language sources, word sources and downloads",
+		Description: "Lists the ids of every language source, word source and file that can be downloaded",
+		Setup: func(flags *flag.FlagSet) func(ctx context.Context, args []string) error {
+			return func(ctx context.Context, args []string) error {
+				fmt.Println("Language sources:")
+				for _, id := range LanguageSourceIds {
+					fmt.Printf("\t%s\n", id)
+				}
+				fmt.Println("Word sources:")
+				for _, id := range WordSourceIds {
+					fmt.Printf("\t%s\n", id)
+				}
+				fmt.Printf("\t%s<path to word list>\n", WordSourceIdPrefix_File)
+				fmt.Println("Downloads:")
+				for _, download := range Downloads() {
+					fmt.Printf("\t%s\n", download)
+				}
+				return nil
+			}
+		},
+	})
+}
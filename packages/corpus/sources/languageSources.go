@@ -1,16 +1,45 @@
 package sources
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"iter"
+)
 
 // Source of words as used in language
 type LanguageSource interface {
 	Alphabet() string
-	// Returns a channel streaming individual words, whch may have punctuation etc.
-	Read() (chan *string, error)
+	// Sequence of individual words, as split up by the source's Tokenizer, which ends at the end of
+	// the source or with an error reading it (including the cancellation of the context)
+	Read(ctx context.Context) iter.Seq2[string, error]
+}
+
+// Language source which can be read by several workers at once, sharing out the work of decoding
+// and tokenizing the source between them
+type ShardedLanguageSource interface {
+	LanguageSource
+	// Splits Read into n sequences which can be consumed concurrently, and which between them yield
+	// the words of the source (in no particular order). See Shard for their lifetime
+	ReadShards(ctx context.Context, n int) []iter.Seq2[string, error]
+}
+
+// Reads the language source as n sequences which can be consumed concurrently, sharing out the
+// decoding and tokenizing of the source if it is a ShardedLanguageSource, or else just its words
+func ReadShards(ctx context.Context, ls LanguageSource, n int) []iter.Seq2[string, error] {
+	if sharded, ok := ls.(ShardedLanguageSource); ok {
+		return sharded.ReadShards(ctx, n)
+	}
+	return Shard(ctx, ls.Read(ctx), n, shardBatchSize)
 }
 
+// Number of values handed to a shard at a time, large enough that the shards rarely wait on each other
+const shardBatchSize = 1024
+
 type LanguageSourceId string
 
+// Letters of the (modern, basic latin) English alphabet
+const englishAlphabet = "abcdefghijklmnopqrstuvwxyz"
+
 const (
 	// Language sourced from wikiextract simple-english dictionary examples with default filters
 	LanguageSourceId_SimpleEn = "we-simple-en"
@@ -23,9 +52,24 @@ const (
 	LanguageSourceId_En = "we-en"
 	// Language source from the wikiextract English dictionary examples
 	LanguageSourceId_EnAll = "we-en-all"
+
+	// Language sourced from the wikiextract English dictionary examples with words in the CSW21 word list
+	LanguageSourceId_EnCsw21 = "we-en-csw21"
+	// Language sourced from the wikiextract English dictionary examples with words in the NSWL2023 word list
+	LanguageSourceId_EnNswl2023 = "we-en-nswl2023"
+
+	// Language sourced from the simple-english wikipedia articles, with words in the default filtered
+	// simple-english dictionary
+	LanguageSourceId_WpSimpleEn = "wp-simple-en"
+	// Language sourced from all simple-english wikipedia articles
+	LanguageSourceId_WpSimpleEnAll = "wp-simple-en-all"
+	// Language sourced from the English wikipedia articles, with words in the default filtered English dictionary
+	LanguageSourceId_WpEn = "wp-en"
+	// Language sourced from all English wikipedia articles
+	LanguageSourceId_WpEnAll = "wp-en-all"
 )
 
-func GetLanguageSource(srcId LanguageSourceId) (LanguageSource, error) {
+func GetLanguageSource(ctx context.Context, srcId LanguageSourceId) (LanguageSource, error) {
 	switch srcId {
 	// All from simple-english examples
 	case LanguageSourceId_SimpleEnAll:
@@ -34,7 +78,7 @@ func GetLanguageSource(srcId LanguageSourceId) (LanguageSource, error) {
 	// All simple-english words from simple-english examples which meet the
 	// ReasonableEnglishWord criteria
 	case LanguageSourceId_SimpleEn:
-		ws, err := newWikiExtractWordSource(WikiExtractLanguage_SimpleEn)
+		ws, err := newWikiExtractWordSource(ctx, WikiExtractLanguage_SimpleEn)
 		if err != nil {
 			return nil, err
 		}
@@ -45,7 +89,7 @@ func GetLanguageSource(srcId LanguageSourceId) (LanguageSource, error) {
 
 	// All simple-english words from the full english examples
 	case LanguageSourceId_SimpleEnFromEnExamples:
-		ws, err := newWikiExtractWordSource(WikiExtractLanguage_SimpleEn)
+		ws, err := newWikiExtractWordSource(ctx, WikiExtractLanguage_SimpleEn)
 		if err != nil {
 			return nil, err
 		}
@@ -54,7 +98,7 @@ func GetLanguageSource(srcId LanguageSourceId) (LanguageSource, error) {
 	// All English words from the English examples that meet the
 	// Reasonable English Word criteria
 	case LanguageSourceId_En:
-		ws, err := newWikiExtractWordSource(WikiExtractLanguage_En)
+		ws, err := newWikiExtractWordSource(ctx, WikiExtractLanguage_En)
 		if err != nil {
 			return nil, err
 		}
@@ -67,6 +111,52 @@ func GetLanguageSource(srcId LanguageSourceId) (LanguageSource, error) {
 	case LanguageSourceId_EnAll:
 		return newWikiExtractLanguageSource(WikiExtractLanguage_En), nil
 
+	// All CSW21 words from the English examples
+	case LanguageSourceId_EnCsw21:
+		ws, err := GetWordSource(ctx, WordSourceId_Csw21)
+		if err != nil {
+			return nil, err
+		}
+		return FilterLanguageSource(newWikiExtractLanguageSource(WikiExtractLanguage_En), ws), nil
+
+	// All NSWL2023 words from the English examples
+	case LanguageSourceId_EnNswl2023:
+		ws, err := GetWordSource(ctx, WordSourceId_Nswl2023)
+		if err != nil {
+			return nil, err
+		}
+		return FilterLanguageSource(newWikiExtractLanguageSource(WikiExtractLanguage_En), ws), nil
+
+	// All words from the simple-english wikipedia
+	case LanguageSourceId_WpSimpleEnAll:
+		return newWikipediaLanguageSource(WikipediaLanguage_SimpleEn), nil
+
+	// All simple-english words from the simple-english wikipedia which meet the
+	// ReasonableEnglishWord criteria
+	case LanguageSourceId_WpSimpleEn:
+		ws, err := newWikiExtractWordSource(ctx, WikiExtractLanguage_SimpleEn)
+		if err != nil {
+			return nil, err
+		}
+		return FilterLanguageSource(
+			newWikipediaLanguageSource(WikipediaLanguage_SimpleEn),
+			FilterWordSource(ws, ReasonableEnglishWord)), nil
+
+	// All words from the English wikipedia
+	case LanguageSourceId_WpEnAll:
+		return newWikipediaLanguageSource(WikipediaLanguage_En), nil
+
+	// All English words from the English wikipedia which meet the
+	// ReasonableEnglishWord criteria
+	case LanguageSourceId_WpEn:
+		ws, err := newWikiExtractWordSource(ctx, WikiExtractLanguage_En)
+		if err != nil {
+			return nil, err
+		}
+		return FilterLanguageSource(
+			newWikipediaLanguageSource(WikipediaLanguage_En),
+			FilterWordSource(ws, ReasonableEnglishWord)), nil
+
 	default:
 		return nil, fmt.Errorf("unsupported language source")
 	}
@@ -81,24 +171,18 @@ func FilterLanguageSource(ls LanguageSource, ws WordSource) *filteredLanguageSou
 	return &filteredLanguageSource{ls: ls, ws: ws}
 }
 func (fls *filteredLanguageSource) Alphabet() string { return fls.ls.Alphabet() }
-func (fls *filteredLanguageSource) Read() (chan *string, error) {
-	inCh, err := fls.ls.Read()
-	if err != nil {
-		return nil, err
-	}
-	outCh := make(chan *string)
-	go func(inCh chan *string, outCh chan *string, ws WordSource) {
-		defer func(ch chan *string) {
-			close(ch)
-		}(outCh)
-		for {
-			word := <-inCh
-			if word == nil || ws.GetWord(*word) != nil {
-				outCh <- word
-			}
-		}
-
-	}(inCh, outCh, fls.ws)
+func (fls *filteredLanguageSource) Read(ctx context.Context) iter.Seq2[string, error] {
+	return Filter(fls.ls.Read(ctx), func(word string) bool {
+		return fls.ws.GetWord(word) != nil
+	})
+}
 
-	return outCh, nil
+func (fls *filteredLanguageSource) ReadShards(ctx context.Context, n int) []iter.Seq2[string, error] {
+	shards := ReadShards(ctx, fls.ls, n)
+	for i, shard := range shards {
+		shards[i] = Filter(shard, func(word string) bool {
+			return fls.ws.GetWord(word) != nil
+		})
+	}
+	return shards
 }
@@ -0,0 +1,193 @@
+package sources
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// Sequences of values which may fail part way through, such as the words read by a LanguageSource.
+// An error is yielded (with the zero value) as the last element of the sequence, so that consumers
+// ranging over it can stop on the first error, e.g.
+//
+//	for word, err := range language.Read(ctx) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+
+// The values of seq which keep accepts, along with any error
+func Filter[T any](seq iter.Seq2[T, error], keep func(T) bool) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for value, err := range seq {
+			if err != nil || keep(value) {
+				if !yield(value, err) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// The values of seq transformed by f, along with any error
+func Map[T any, U any](seq iter.Seq2[T, error], f func(T) U) iter.Seq2[U, error] {
+	return func(yield func(U, error) bool) {
+		for value, err := range seq {
+			if err != nil {
+				var zero U
+				yield(zero, err)
+				return
+			}
+			if !yield(f(value), nil) {
+				return
+			}
+		}
+	}
+}
+
+// The first n values of seq, stopping it once they have been yielded
+func Take[T any](seq iter.Seq2[T, error], n int) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		if n <= 0 {
+			return
+		}
+		taken := 0
+		for value, err := range seq {
+			if !yield(value, err) || err != nil {
+				return
+			}
+			taken++
+			if taken == n {
+				return
+			}
+		}
+	}
+}
+
+// Splits seq into n sequences which can be consumed concurrently, e.g. by a pool of workers, which
+// between them yield every value of seq. A goroutine reads seq, handing values out in batches of
+// batchSize to whichever sequence asks for them first, and an error reading seq is yielded by each of
+// the sequences. The reading stops once the sequences have all been consumed, or when the context is
+// cancelled, which callers must do if they stop consuming early
+func Shard[T any](ctx context.Context, seq iter.Seq2[T, error], n int, batchSize int) []iter.Seq2[T, error] {
+	batches := make(chan []T, n)
+	var err error
+	readBatches(seq, batchSize, func(batch []T) bool {
+		select {
+		case batches <- batch:
+			return true
+		case <-ctx.Done():
+			err = ctx.Err()
+			return false
+		}
+	}, func(readErr error) {
+		if readErr != nil {
+			err = readErr
+		}
+		// err is written before the batches are closed, so it is visible to the sequences
+		close(batches)
+	})
+
+	shards := make([]iter.Seq2[T, error], n)
+	for i := range shards {
+		shards[i] = batchedSeq(batches, &err, func() {})
+	}
+	return shards
+}
+
+// Splits seq into two sequences which each yield every value of seq (and any error reading it), so
+// that two consumers can range over it concurrently, e.g. to count the words of a language while
+// saving them. A goroutine reads seq, handing the values to both sequences in batches of batchSize,
+// so one consumer can only get a couple of batches ahead of the other. A sequence which stops early
+// no longer holds the other back, and the reading stops once both sequences have been consumed or
+// stopped, or when the context is cancelled, which callers must do if either is never consumed
+func Tee[T any](ctx context.Context, seq iter.Seq2[T, error], batchSize int) (iter.Seq2[T, error], iter.Seq2[T, error]) {
+	type branch struct {
+		batches chan []T
+		// closed once the branch's sequence stops early
+		stopped chan struct{}
+	}
+	branches := [2]branch{}
+	for i := range branches {
+		branches[i] = branch{batches: make(chan []T, 1), stopped: make(chan struct{})}
+	}
+	var err error
+	readBatches(seq, batchSize, func(batch []T) bool {
+		live := 0
+		for _, b := range branches {
+			select {
+			case b.batches <- batch:
+				live++
+			case <-b.stopped:
+			case <-ctx.Done():
+				err = ctx.Err()
+				return false
+			}
+		}
+		return live > 0
+	}, func(readErr error) {
+		if readErr != nil {
+			err = readErr
+		}
+		for _, b := range branches {
+			close(b.batches)
+		}
+	})
+
+	stop := func(b branch) func() {
+		return sync.OnceFunc(func() { close(b.stopped) })
+	}
+	return batchedSeq(branches[0].batches, &err, stop(branches[0])), batchedSeq(branches[1].batches, &err, stop(branches[1]))
+}
+
+// Reads seq on a new goroutine, handing its values in batches of up to batchSize to send, which
+// reports whether to keep reading. Once seq ends or send stops the reading, done is called with
+// any error reading seq
+func readBatches[T any](seq iter.Seq2[T, error], batchSize int, send func([]T) bool, done func(error)) {
+	go func() {
+		batch := make([]T, 0, batchSize)
+		for value, err := range seq {
+			if err != nil {
+				// the values read before the error are still handed out
+				if len(batch) > 0 && !send(batch) {
+					done(nil)
+					return
+				}
+				done(err)
+				return
+			}
+			batch = append(batch, value)
+			if len(batch) == batchSize {
+				if !send(batch) {
+					done(nil)
+					return
+				}
+				batch = make([]T, 0, batchSize)
+			}
+		}
+		if len(batch) > 0 {
+			send(batch)
+		}
+		done(nil)
+	}()
+}
+
+// Sequence of the values of the batches, followed by the error once they are closed, if there is
+// one. The batches are shared, so must not be modified. stop is called if the sequence stops early
+func batchedSeq[T any](batches <-chan []T, err *error, stop func()) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for batch := range batches {
+			for _, value := range batch {
+				if !yield(value, nil) {
+					stop()
+					return
+				}
+			}
+		}
+		if *err != nil {
+			var zero T
+			yield(zero, *err)
+		}
+	}
+}
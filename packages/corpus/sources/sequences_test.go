@@ -0,0 +1,227 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errTestRead = errors.New("read failed")
+
+// Sequence of 0 to n-1, followed by the error if there is one. Closes stopped once it stops,
+// whether it ran out or was stopped early
+func countTo(n int, err error, stopped chan struct{}) iter.Seq2[int, error] {
+	return func(yield func(int, error) bool) {
+		if stopped != nil {
+			defer close(stopped)
+		}
+		for i := range n {
+			if !yield(i, nil) {
+				return
+			}
+		}
+		if err != nil {
+			yield(0, err)
+		}
+	}
+}
+
+// Endless sequence of 0, 1, 2..., which closes stopped once it is stopped
+func countForever(stopped chan struct{}) iter.Seq2[int, error] {
+	return countTo(int(^uint(0)>>1), nil, stopped)
+}
+
+// Values of the sequence and the error ending it, if any
+func collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	values := []T{}
+	for value, err := range seq {
+		if err != nil {
+			return values, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// Fails the test unless stopped is closed soon
+func waitStopped(t *testing.T, stopped chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s did not stop reading", what)
+	}
+}
+
+func even(i int) bool { return i%2 == 0 }
+
+func TestFilter(t *testing.T) {
+	values, err := collect(Filter(countTo(7, errTestRead, nil), even))
+	if !slices.Equal(values, []int{0, 2, 4, 6}) || err != errTestRead {
+		t.Errorf("filtered %v, %v", values, err)
+	}
+	stopped := make(chan struct{})
+	for value := range Filter(countForever(stopped), even) {
+		if value == 4 {
+			break
+		}
+	}
+	waitStopped(t, stopped, "filter")
+}
+
+func TestMap(t *testing.T) {
+	double := func(i int) int { return 2 * i }
+	values, err := collect(Map(countTo(3, nil, nil), double))
+	if !slices.Equal(values, []int{0, 2, 4}) || err != nil {
+		t.Errorf("mapped %v, %v", values, err)
+	}
+	mapped := 0
+	for value, err := range Map(countTo(3, errTestRead, nil), double) {
+		if err != nil {
+			if value != 0 || err != errTestRead {
+				t.Errorf("mapped error %v with %d", err, value)
+			}
+			break
+		}
+		mapped++
+	}
+	if mapped != 3 {
+		t.Errorf("mapped %d values before the error, want 3", mapped)
+	}
+	stopped := make(chan struct{})
+	for value := range Map(countForever(stopped), double) {
+		if value == 4 {
+			break
+		}
+	}
+	waitStopped(t, stopped, "map")
+}
+
+func TestTake(t *testing.T) {
+	stopped := make(chan struct{})
+	values, err := collect(Take(countForever(stopped), 3))
+	if !slices.Equal(values, []int{0, 1, 2}) || err != nil {
+		t.Errorf("took %v, %v", values, err)
+	}
+	waitStopped(t, stopped, "take")
+
+	values, err = collect(Take(countTo(2, errTestRead, nil), 5))
+	if !slices.Equal(values, []int{0, 1}) || err != errTestRead {
+		t.Errorf("took %v, %v from a failing sequence", values, err)
+	}
+	values, _ = collect(Take(countTo(2, nil, nil), 0))
+	if len(values) != 0 {
+		t.Errorf("took %v of none", values)
+	}
+}
+
+func TestTee(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, b := Tee(ctx, countTo(100, errTestRead, nil), 7)
+	var wg sync.WaitGroup
+	results := [2][]int{}
+	errs := [2]error{}
+	for i, seq := range []iter.Seq2[int, error]{a, b} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = collect(seq)
+		}()
+	}
+	wg.Wait()
+	for i := range results {
+		if len(results[i]) != 100 || !slices.IsSorted(results[i]) || errs[i] != errTestRead {
+			t.Errorf("tee %d yielded %d values and %v", i, len(results[i]), errs[i])
+		}
+	}
+}
+
+func TestTeeStopEarly(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan struct{})
+	a, b := Tee(ctx, countTo(100, nil, stopped), 3)
+	// the first stops early, and the second still gets every value without it
+	for value := range a {
+		if value == 4 {
+			break
+		}
+	}
+	values, err := collect(b)
+	if len(values) != 100 || err != nil {
+		t.Errorf("other tee yielded %d values and %v", len(values), err)
+	}
+	waitStopped(t, stopped, "tee")
+
+	// the reading stops once both have stopped
+	stopped = make(chan struct{})
+	a, b = Tee(ctx, countForever(stopped), 3)
+	for _, seq := range []iter.Seq2[int, error]{a, b} {
+		for value := range seq {
+			if value == 4 {
+				break
+			}
+		}
+	}
+	waitStopped(t, stopped, "stopped tees")
+}
+
+func TestTeeCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	a, _ := Tee(ctx, countForever(stopped), 3)
+	cancel()
+	waitStopped(t, stopped, "cancelled tee")
+	if _, err := collect(a); err != context.Canceled {
+		t.Errorf("cancelled tee ended with %v", err)
+	}
+}
+
+func TestShard(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	shards := Shard(ctx, countTo(100, errTestRead, nil), 4, 3)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	all := []int{}
+	for _, shard := range shards {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values, err := collect(shard)
+			if err != errTestRead {
+				t.Errorf("shard ended with %v", err)
+			}
+			mu.Lock()
+			all = append(all, values...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	slices.Sort(all)
+	values, _ := collect(countTo(100, nil, nil))
+	if !slices.Equal(all, values) {
+		t.Errorf("shards yielded %v", all)
+	}
+}
+
+func TestShardCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	shards := Shard(ctx, countForever(stopped), 2, 3)
+	for value := range shards[0] {
+		if value >= 10 {
+			break
+		}
+	}
+	cancel()
+	waitStopped(t, stopped, "cancelled shards")
+	if _, err := collect(shards[1]); err != context.Canceled {
+		t.Errorf("cancelled shard ended with %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+package sources
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Splits running text (e.g. example sentences) into the individual words used for analysis
+type Tokenizer interface {
+	// Returns the words in the text, in order, with punctuation and whitespace removed
+	Tokenize(text string) []string
+}
+
+// How apostrophes within a word (e.g. "don't", "o'clock") are handled.
+// Apostrophes at the start or end of a word are always treated as punctuation
+type ApostrophePolicy int
+
+const (
+	// Keep the apostrophe as part of the word: "don't" => ["don't"]
+	ApostrophePolicy_Keep ApostrophePolicy = iota
+	// Treat the apostrophe as a word boundary: "don't" => ["don", "t"]
+	ApostrophePolicy_Split
+	// Drop the apostrophe and join the parts: "don't" => ["dont"]
+	ApostrophePolicy_Remove
+)
+
+// How hyphens within a word (e.g. "well-known") are handled.
+// Hyphens at the start or end of a word, as well as dashes, are always treated as punctuation
+type HyphenPolicy int
+
+const (
+	// Keep the hyphen as part of the word: "well-known" => ["well-known"]
+	HyphenPolicy_Keep HyphenPolicy = iota
+	// Treat the hyphen as a word boundary: "well-known" => ["well", "known"]
+	HyphenPolicy_Split
+	// Drop the hyphen and join the parts: "well-known" => ["wellknown"]
+	HyphenPolicy_Remove
+)
+
+// Configuration of the default Unicode word-boundary Tokenizer
+type TokenizerConfig struct {
+	Apostrophes ApostrophePolicy
+	Hyphens     HyphenPolicy
+	// Lower-case every token, so "Dog" and "dog" are counted as the same word
+	FoldCase bool
+	// Drop tokens that contain digits, e.g. "1984" or "3rd"
+	StripNumbers bool
+	// Drop whitespace-delimited chunks that look like URLs or email addresses
+	StripUrls bool
+}
+
+// Tokenizer configuration used for languages without their own entry in tokenizerConfigs
+var defaultTokenizerConfig = TokenizerConfig{
+	Apostrophes:  ApostrophePolicy_Keep,
+	Hyphens:      HyphenPolicy_Split,
+	FoldCase:     true,
+	StripNumbers: true,
+	StripUrls:    true,
+}
+
+// Per-language tokenizer configuration, keyed by language code (e.g. "en")
+var tokenizerConfigs = map[string]TokenizerConfig{
+	"en": {
+		Apostrophes:  ApostrophePolicy_Keep,
+		Hyphens:      HyphenPolicy_Split,
+		FoldCase:     true,
+		StripNumbers: true,
+		StripUrls:    true,
+	},
+}
+
+// Returns the tokenizer configured for the language code, falling back to a default configuration
+func TokenizerForLanguage(code string) Tokenizer {
+	config, ok := tokenizerConfigs[code]
+	if !ok {
+		config = defaultTokenizerConfig
+	}
+	return NewTokenizer(config)
+}
+
+// Creates a Tokenizer which segments text at Unicode word boundaries, in the spirit of
+// (a simplified version of) the UAX #29 word-boundary rules: letters, combining marks and
+// digits form words, apostrophes and hyphens may join word characters, periods and commas may
+// join digits, and everything else (whitespace, dashes, punctuation, symbols) separates words
+func NewTokenizer(config TokenizerConfig) Tokenizer {
+	return &wordTokenizer{config: config}
+}
+
+type wordTokenizer struct {
+	config TokenizerConfig
+}
+
+func (t *wordTokenizer) Tokenize(text string) (tokens []string) {
+	for _, chunk := range strings.FieldsFunc(text, unicode.IsSpace) {
+		if t.config.StripUrls && looksLikeUrl(chunk) {
+			continue
+		}
+		for _, token := range segmentWords(chunk) {
+			if token = t.applyPolicies(token); token == "" {
+				continue
+			}
+			for _, part := range strings.Fields(token) {
+				if t.config.StripNumbers && strings.IndexFunc(part, unicode.IsDigit) >= 0 {
+					continue
+				}
+				if t.config.FoldCase {
+					part = strings.ToLower(part)
+				}
+				tokens = append(tokens, part)
+			}
+		}
+	}
+	return
+}
+
+// Rewrites or removes apostrophes and hyphens within the token according to the policies,
+// replacing them with a space where the token should be split
+func (t *wordTokenizer) applyPolicies(token string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case isApostrophe(r):
+			switch t.config.Apostrophes {
+			case ApostrophePolicy_Split:
+				return ' '
+			case ApostrophePolicy_Remove:
+				return -1
+			}
+			return '\''
+		case isHyphen(r):
+			switch t.config.Hyphens {
+			case HyphenPolicy_Split:
+				return ' '
+			case HyphenPolicy_Remove:
+				return -1
+			}
+			return '-'
+		}
+		return r
+	}, token)
+}
+
+// Splits a chunk of text without whitespace into words, keeping apostrophes and hyphens
+// only when they sit between two word characters, and periods and commas only when
+// they sit between two digits
+func segmentWords(chunk string) (words []string) {
+	runes := []rune(chunk)
+	start := -1
+	for i, r := range runes {
+		joins := false
+		if start >= 0 && i+1 < len(runes) {
+			prev, next := runes[i-1], runes[i+1]
+			switch {
+			case isApostrophe(r) || isHyphen(r):
+				joins = isWordRune(prev) && isWordRune(next)
+			case r == '.' || r == ',':
+				joins = unicode.IsDigit(prev) && unicode.IsDigit(next)
+			}
+		}
+		if isWordRune(r) || joins {
+			if start < 0 {
+				start = i
+			}
+		} else if start >= 0 {
+			words = append(words, string(runes[start:i]))
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r)
+}
+
+func isApostrophe(r rune) bool {
+	return r == '\'' || r == '’' || r == 'ʼ'
+}
+
+func isHyphen(r rune) bool {
+	// hyphen-minus, hyphen, non-breaking hyphen. Dashes (en, em) separate words
+	return r == '-' || r == '‐' || r == '‑'
+}
+
+// Whether a whitespace-delimited chunk of text is a URL or email address
+func looksLikeUrl(chunk string) bool {
+	lower := strings.ToLower(chunk)
+	if strings.Contains(lower, "://") || strings.HasPrefix(lower, "www.") {
+		return true
+	}
+	at := strings.Index(lower, "@")
+	return at > 0 && strings.Contains(lower[at:], ".")
+}
@@ -0,0 +1,58 @@
+package sources
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	english := TokenizerForLanguage("en")
+	cases := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"punctuation", `Hello, world! (Quoted) "words"; done.`, []string{"hello", "world", "quoted", "words", "done"}},
+		{"edge apostrophes", "'Tis the dogs' bone", []string{"tis", "the", "dogs", "bone"}},
+		{"em dash", "wait—what", []string{"wait", "what"}},
+		{"en dash", "pages–here", []string{"pages", "here"}},
+		{"edge hyphens", "-dash- --double", []string{"dash", "double"}},
+		{"whitespace", "tab\tseparated\nlines\r\n  here", []string{"tab", "separated", "lines", "here"}},
+		{"apostrophe", "Can't", []string{"can't"}},
+		{"curly apostrophe", "can’t", []string{"can't"}},
+		{"hyphen", "well-known", []string{"well", "known"}},
+		{"urls", "see https://example.com/a-b and www.example.org or me@example.com.", []string{"see", "and", "or"}},
+		{"digits", "1984 was the 3rd year, 3.14 or 1,000", []string{"was", "the", "year", "or"}},
+		{"unicode letters", "Naïve café ÜBER", []string{"naïve", "café", "über"}},
+		{"combining marks", "cafe\u0301s!", []string{"cafe\u0301s"}},
+	}
+	for _, c := range cases {
+		if got := english.Tokenize(c.text); !slices.Equal(got, c.want) {
+			t.Errorf("%s: Tokenize(%q) = %q, want %q", c.name, c.text, got, c.want)
+		}
+	}
+}
+
+func TestTokenizePolicies(t *testing.T) {
+	cases := []struct {
+		name   string
+		config TokenizerConfig
+		text   string
+		want   []string
+	}{
+		{"keep apostrophes", TokenizerConfig{Apostrophes: ApostrophePolicy_Keep}, "can't", []string{"can't"}},
+		{"split apostrophes", TokenizerConfig{Apostrophes: ApostrophePolicy_Split}, "can't", []string{"can", "t"}},
+		{"remove apostrophes", TokenizerConfig{Apostrophes: ApostrophePolicy_Remove}, "can't", []string{"cant"}},
+		{"keep hyphens", TokenizerConfig{Hyphens: HyphenPolicy_Keep}, "well-known", []string{"well-known"}},
+		{"split hyphens", TokenizerConfig{Hyphens: HyphenPolicy_Split}, "well-known", []string{"well", "known"}},
+		{"remove hyphens", TokenizerConfig{Hyphens: HyphenPolicy_Remove}, "well-known", []string{"wellknown"}},
+		{"keep case", TokenizerConfig{}, "Dog dog", []string{"Dog", "dog"}},
+		{"keep numbers", TokenizerConfig{}, "1984 3rd 3.14 1,000 x.y", []string{"1984", "3rd", "3.14", "1,000", "x", "y"}},
+		{"keep urls", TokenizerConfig{}, "https://example.com", []string{"https", "example", "com"}},
+	}
+	for _, c := range cases {
+		if got := NewTokenizer(c.config).Tokenize(c.text); !slices.Equal(got, c.want) {
+			t.Errorf("%s: Tokenize(%q) = %q, want %q", c.name, c.text, got, c.want)
+		}
+	}
+}
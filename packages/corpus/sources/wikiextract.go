@@ -1,10 +1,15 @@
 package sources
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"iter"
 	"maps"
 	"slices"
 	"strings"
+	"sync/atomic"
 
 	"bufio"
 	"compress/gzip"
@@ -27,8 +32,8 @@ var wikiextractFiles = map[WikiExtractLanguage]string{
 }
 
 var wikiExtractAlphabets = map[WikiExtractLanguage]string{
-	"we-en":        "abcdefghiklmnopqrstuvwxyz",
-	"we-simple-en": "abcdefghiklmnopqrstuvwxyz",
+	"we-en":        englishAlphabet,
+	"we-simple-en": englishAlphabet,
 }
 
 var languageCode = map[WikiExtractLanguage]string{
@@ -39,7 +44,7 @@ var languageCode = map[WikiExtractLanguage]string{
 // Downloads wiktionary extracts produced by the https://github.com/tatuylonen/wiktextract project
 // from the archive at https://kaikki.org/dictionary/rawdata.html. These are gzip-compressed JSONL
 // files whose structure is documented in the above github
-func DownloadWikiExtract(language WikiExtractLanguage) (string, error) {
+func DownloadWikiExtract(ctx context.Context, language WikiExtractLanguage) (string, error) {
 	url, ok := wikiextractFiles[language]
 	if !ok {
 		return "", fmt.Errorf("invalid language to download: %s", language)
@@ -49,7 +54,7 @@ func DownloadWikiExtract(language WikiExtractLanguage) (string, error) {
 		return "", err
 	}
 	if !utils.FileExists(target) {
-		err = utils.DownloadFile(target, url)
+		err = utils.DownloadFile(ctx, target, url)
 		if err != nil {
 			return "", err
 		}
@@ -88,57 +93,53 @@ type WeExample struct {
 }
 
 type wikiExtractWordSource struct {
+	language   WikiExtractLanguage
 	words      map[string]*Word
 	categories map[int]string
 }
 
-func newWikiExtractWordSource(language WikiExtractLanguage) (*wikiExtractWordSource, error) {
-	w := wikiExtractWordSource{words: map[string]*Word{}, categories: map[int]string{}}
+func newWikiExtractWordSource(ctx context.Context, language WikiExtractLanguage) (*wikiExtractWordSource, error) {
+	w := wikiExtractWordSource{language: language, words: map[string]*Word{}, categories: map[int]string{}}
 	invertedCats := map[string]int{}
 
-	entryCh, err := ParseWikiExtract(language)
-	if err != nil {
-		return nil, err
-	}
-
 	analyzed := 0
-	for {
-		entry := <-entryCh
-		if entry == nil {
-
+	for entry, err := range ParseWikiExtract(ctx, language) {
+		if err != nil {
+			return nil, err
+		}
+		analyzed++
+		if analyzed%10000 == 0 {
 			fmt.Fprintf(os.Stderr,
-				"Finished reading dictionary, %d words\n", analyzed)
-			break
-		} else {
-			analyzed++
-			if analyzed%10000 == 0 {
-				fmt.Fprintf(os.Stderr,
-					"Added %d words to the %s dictionary (latest: %s)\n",
-					analyzed, language, entry.Word)
-			}
-			cat, ok := invertedCats[entry.Pos]
-			if !ok {
-				cat = len(invertedCats)
-				invertedCats[entry.Pos] = cat
-				w.categories[cat] = entry.Pos
+				"Added %d words to the %s dictionary (latest: %s)\n",
+				analyzed, language, entry.Word)
+		}
+		cat, ok := invertedCats[entry.Pos]
+		if !ok {
+			cat = len(invertedCats)
+			invertedCats[entry.Pos] = cat
+			w.categories[cat] = entry.Pos
+		}
+		currentWord, ok := w.words[entry.Word]
+		if ok {
+			if !slices.Contains(currentWord.Categories, cat) {
+				currentWord.Categories = append(currentWord.Categories, cat)
 			}
-			currentWord, ok := w.words[entry.Word]
-			if ok {
-				if !slices.Contains(currentWord.Categories, cat) {
-					currentWord.Categories = append(currentWord.Categories, cat)
-				}
-			} else {
-				w.words[entry.Word] = &Word{
-					Word:       entry.Word,
-					Categories: []int{cat},
-				}
+		} else {
+			w.words[entry.Word] = &Word{
+				Word:       entry.Word,
+				Categories: []int{cat},
 			}
 		}
 	}
+	fmt.Fprintf(os.Stderr, "Finished reading dictionary, %d words\n", analyzed)
 
 	return &w, nil
 }
 
+func (w *wikiExtractWordSource) Alphabet() string {
+	return wikiExtractAlphabets[w.language]
+}
+
 func (w *wikiExtractWordSource) GetCategory(catId int) string {
 	cat, ok := w.categories[catId]
 	if !ok {
@@ -159,94 +160,139 @@ func (w *wikiExtractWordSource) GetWordList() []*Word {
 }
 
 type wikiExtractLanguageSource struct {
-	language WikiExtractLanguage
+	language  WikiExtractLanguage
+	tokenizer Tokenizer
 }
 
 func newWikiExtractLanguageSource(language WikiExtractLanguage) *wikiExtractLanguageSource {
-	return &wikiExtractLanguageSource{language: language}
+	return &wikiExtractLanguageSource{
+		language:  language,
+		tokenizer: TokenizerForLanguage(languageCode[language]),
+	}
 }
 
 func (w *wikiExtractLanguageSource) Alphabet() string {
 	return wikiExtractAlphabets[w.language]
 }
 
-func (w *wikiExtractLanguageSource) Read() (chan *string, error) {
+func (w *wikiExtractLanguageSource) Read(ctx context.Context) iter.Seq2[string, error] {
+	return w.examples(ParseWikiExtract(ctx, w.language), &atomic.Int64{})
+}
 
-	inCh, err := ParseWikiExtract(w.language)
-	if err != nil {
-		return nil, err
+// Shares out the lines of the file, so that each shard parses its own lines and tokenizes their examples
+func (w *wikiExtractLanguageSource) ReadShards(ctx context.Context, n int) []iter.Seq2[string, error] {
+	analyzed := &atomic.Int64{}
+	shards := []iter.Seq2[string, error]{}
+	for _, lines := range Shard(ctx, readWikiExtractLines(ctx, w.language), n, shardBatchSize) {
+		shards = append(shards, w.examples(parseWikiExtractLines(lines, w.language), analyzed))
 	}
-	outCh := make(chan *string)
-	analyzed := 0
-	go func(inCh chan *WeWord, outCh chan *string) {
-		defer func(outCh chan *string) {
-			close(outCh)
-		}(outCh)
-
-		for {
-			entry := <-inCh
-			if entry == nil {
-				outCh <- nil
-				break
-			} else {
-				analyzed++
-				if analyzed%10000 == 0 {
-					fmt.Fprintf(os.Stderr,
-						"Added  %d examples from the %s dictionary (latest: %s)\n",
-						analyzed, w.language, entry.Word)
-				}
-				for _, s := range entry.Senses {
-					for _, e := range s.Examples {
-						for _, w := range strings.Split(e.Text, " ") {
-							outCh <- &w
+	return shards
+}
+
+// Tokens of the example sentences of the entries, counting the entries analyzed for progress reports
+func (w *wikiExtractLanguageSource) examples(entries iter.Seq2[*WeWord, error], analyzed *atomic.Int64) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for entry, err := range entries {
+			if err != nil {
+				yield("", err)
+				return
+			}
+			if count := analyzed.Add(1); count%10000 == 0 {
+				fmt.Fprintf(os.Stderr,
+					"Added  %d examples from the %s dictionary (latest: %s)\n",
+					count, w.language, entry.Word)
+			}
+			for _, s := range entry.Senses {
+				for _, e := range s.Examples {
+					for _, token := range w.tokenizer.Tokenize(e.Text) {
+						if !yield(token, nil) {
+							return
 						}
 					}
 				}
 			}
 		}
-	}(inCh, outCh)
-	return outCh, nil
+	}
 }
 
-// Downloads (if necessary) and parses the WikiExtract gzipped JSONL langauge file
-func ParseWikiExtract(language WikiExtractLanguage) (chan *WeWord, error) {
-	WikiExtractFile, err := DownloadWikiExtract(language)
-	if err != nil {
-		return nil, err
-	}
-	// reads a gzipped jsonl file from wikiextract
-	rawf, err := os.Open(WikiExtractFile)
-	if err != nil {
-		return nil, err
-	}
-	rawContents, err := gzip.NewReader(rawf)
-	if err != nil {
-		return nil, err
+// Downloads (if necessary) and parses the WikiExtract gzipped JSONL langauge file, yielding the
+// entries in the language. The file is read as the sequence is iterated, and closed when it stops
+func ParseWikiExtract(ctx context.Context, language WikiExtractLanguage) iter.Seq2[*WeWord, error] {
+	return parseWikiExtractLines(readWikiExtractLines(ctx, language), language)
+}
+
+// Non-empty line of a WikiExtract file, holding a single JSON entry
+type weLine struct {
+	file   string
+	number int
+	text   []byte
+}
+
+// Downloads (if necessary) and decompresses the WikiExtract file, yielding its non-empty lines
+func readWikiExtractLines(ctx context.Context, language WikiExtractLanguage) iter.Seq2[weLine, error] {
+	return func(yield func(weLine, error) bool) {
+		WikiExtractFile, err := DownloadWikiExtract(ctx, language)
+		if err != nil {
+			yield(weLine{}, err)
+			return
+		}
+		utils.RecordInput(ctx, WikiExtractFile)
+		// reads a gzipped jsonl file from wikiextract
+		rawf, err := os.Open(WikiExtractFile)
+		if err != nil {
+			yield(weLine{}, err)
+			return
+		}
+		defer rawf.Close()
+		rawContents, err := gzip.NewReader(rawf)
+		if err != nil {
+			yield(weLine{}, fmt.Errorf("reading %s: %w", WikiExtractFile, err))
+			return
+		}
+		defer rawContents.Close()
+
+		contents := bufio.NewReader(rawContents)
+		for lineNumber := 1; ; lineNumber++ {
+			if err := ctx.Err(); err != nil {
+				yield(weLine{}, err)
+				return
+			}
+			line, readErr := contents.ReadBytes('\n')
+			if readErr != nil && readErr != io.EOF {
+				yield(weLine{}, fmt.Errorf("reading %s: %w", WikiExtractFile, readErr))
+				return
+			}
+			if len(bytes.TrimSpace(line)) > 0 && !yield(weLine{WikiExtractFile, lineNumber, line}, nil) {
+				return
+			}
+			if readErr == io.EOF {
+				return
+			}
+		}
 	}
+}
+
+// Parses the lines of a WikiExtract file, yielding the entries in the language
+func parseWikiExtractLines(lines iter.Seq2[weLine, error], language WikiExtractLanguage) iter.Seq2[*WeWord, error] {
 	// Filter to only include words in the target language,
 	// because by default wiktionary includes definitions in the entry language for words in all languages
 	entryLanguage := languageCode[language]
-
-	bufferedContents := bufio.NewReader(rawContents)
-	ch := make(chan *WeWord)
-	go func(ch chan *WeWord, contents *bufio.Reader) {
-		defer func(ch chan *WeWord) {
-			close(ch)
-		}(ch)
-		for {
-			line, err := contents.ReadBytes('\n')
+	return func(yield func(*WeWord, error) bool) {
+		for line, err := range lines {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			word, err := parseObj(line.text)
 			if err != nil {
-				// stop reading when we encounter any error and send a nil
-				ch <- nil
+				yield(nil, fmt.Errorf("parsing line %d of %s: %w", line.number, line.file, err))
+				return
+			}
+			if word.LangCode == string(entryLanguage) && !yield(word, nil) {
 				return
 			}
-			word, err := parseObj(line)
-			if err == nil && word.LangCode == string(entryLanguage) {
-				ch <- word
-			} // ignore regular errors
 		}
-	}(ch, bufferedContents)
-	return ch, nil
+	}
 }
 
 func parseObjIntoWeWord(obj []byte, word *WeWord) error {
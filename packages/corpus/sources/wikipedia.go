@@ -0,0 +1,319 @@
+package sources
+
+import (
+	"compress/bzip2"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"iter"
+	"os"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/digitaltembo/motli/packages/corpus/utils"
+)
+
+type WikipediaLanguage string
+
+const (
+	WikipediaLanguage_En       = "wp-en"
+	WikipediaLanguage_SimpleEn = "wp-simple-en"
+)
+
+var wikipediaFiles = map[WikipediaLanguage]string{
+	"wp-en":        "https://dumps.wikimedia.org/enwiki/latest/enwiki-latest-pages-articles-multistream.xml.bz2",
+	"wp-simple-en": "https://dumps.wikimedia.org/simplewiki/latest/simplewiki-latest-pages-articles-multistream.xml.bz2",
+}
+
+var wikipediaAlphabets = map[WikipediaLanguage]string{
+	"wp-en":        englishAlphabet,
+	"wp-simple-en": englishAlphabet,
+}
+
+var wikipediaLanguageCode = map[WikipediaLanguage]string{
+	"wp-en":        "en",
+	"wp-simple-en": "en",
+}
+
+// Downloads the latest pages-articles dump of the Wikipedia in the given language from
+// https://dumps.wikimedia.org. These are bz2-compressed MediaWiki XML files, and the English one is large (~20gb)
+func DownloadWikipedia(ctx context.Context, language WikipediaLanguage) (string, error) {
+	url, ok := wikipediaFiles[language]
+	if !ok {
+		return "", fmt.Errorf("invalid wikipedia to download: %s", language)
+	}
+	target, err := utils.WikipediaFile(string(language))
+	if err != nil {
+		return "", err
+	}
+	if !utils.FileExists(target) {
+		err = utils.DownloadFile(ctx, target, url)
+		if err != nil {
+			return "", err
+		}
+	}
+	return target, nil
+}
+
+// Structure of a page in a MediaWiki XML dump, as defined in
+// https://www.mediawiki.org/xml/export-0.11.xsd, with most fields ignored for our uses
+type WpPage struct {
+	// Title of the page
+	Title string `xml:"title"`
+	// Namespace of the page, where 0 is the main namespace of articles
+	Ns int `xml:"ns"`
+	// Set if the page is just a redirect to another page
+	Redirect *struct{} `xml:"redirect"`
+	// The latest revision of the page
+	Revision WpRevision `xml:"revision"`
+}
+
+// Structure of a revision of a page in a MediaWiki XML dump
+type WpRevision struct {
+	// Wikitext contents of the page
+	Text string `xml:"text"`
+}
+
+type wikipediaLanguageSource struct {
+	language  WikipediaLanguage
+	tokenizer Tokenizer
+}
+
+func newWikipediaLanguageSource(language WikipediaLanguage) *wikipediaLanguageSource {
+	return &wikipediaLanguageSource{
+		language:  language,
+		tokenizer: TokenizerForLanguage(wikipediaLanguageCode[language]),
+	}
+}
+
+func (w *wikipediaLanguageSource) Alphabet() string {
+	return wikipediaAlphabets[w.language]
+}
+
+func (w *wikipediaLanguageSource) Read(ctx context.Context) iter.Seq2[string, error] {
+	return w.articles(ParseWikipedia(ctx, w.language), &atomic.Int64{})
+}
+
+// Decompressing and decoding the XML dump can't be shared out, but each shard strips and tokenizes
+// its own pages, which is the bulk of the work
+func (w *wikipediaLanguageSource) ReadShards(ctx context.Context, n int) []iter.Seq2[string, error] {
+	analyzed := &atomic.Int64{}
+	shards := []iter.Seq2[string, error]{}
+	// pages can be large, so fewer of them are handed out at a time
+	for _, pages := range Shard(ctx, ParseWikipedia(ctx, w.language), n, shardBatchSize/16) {
+		shards = append(shards, w.articles(pages, analyzed))
+	}
+	return shards
+}
+
+// Tokens of the prose of the pages, counting the pages analyzed for progress reports
+func (w *wikipediaLanguageSource) articles(pages iter.Seq2[*WpPage, error], analyzed *atomic.Int64) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for page, err := range pages {
+			if err != nil {
+				yield("", err)
+				return
+			}
+			if count := analyzed.Add(1); count%1000 == 0 {
+				fmt.Fprintf(os.Stderr,
+					"Added %d articles from the %s wikipedia (latest: %s)\n",
+					count, w.language, page.Title)
+			}
+			for _, token := range w.tokenizer.Tokenize(StripWikitext(page.Revision.Text)) {
+				if !yield(token, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Downloads (if necessary) and parses the bz2-compressed Wikipedia XML dump, yielding the article
+// pages (skipping redirects and pages outside of the main namespace). The file is read as the
+// sequence is iterated, and closed when it stops
+func ParseWikipedia(ctx context.Context, language WikipediaLanguage) iter.Seq2[*WpPage, error] {
+	return func(yield func(*WpPage, error) bool) {
+		wikipediaFile, err := DownloadWikipedia(ctx, language)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		utils.RecordInput(ctx, wikipediaFile)
+		for page, err := range parseWikipediaFile(ctx, wikipediaFile) {
+			if !yield(page, err) {
+				return
+			}
+		}
+	}
+}
+
+// Parses the article pages of a bz2-compressed MediaWiki XML dump
+func parseWikipediaFile(ctx context.Context, wikipediaFile string) iter.Seq2[*WpPage, error] {
+	return func(yield func(*WpPage, error) bool) {
+		rawf, err := os.Open(wikipediaFile)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer rawf.Close()
+		decoder := xml.NewDecoder(bzip2.NewReader(rawf))
+
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+			token, err := decoder.Token()
+			if err == io.EOF {
+				return
+			} else if err != nil {
+				yield(nil, fmt.Errorf("reading %s: %w", wikipediaFile, err))
+				return
+			}
+			start, ok := token.(xml.StartElement)
+			if !ok || start.Name.Local != "page" {
+				continue
+			}
+			page := WpPage{}
+			if err := decoder.DecodeElement(&page, &start); err != nil {
+				yield(nil, fmt.Errorf("parsing page of %s: %w", wikipediaFile, err))
+				return
+			}
+			if page.Ns == 0 && page.Redirect == nil && !yield(&page, nil) {
+				return
+			}
+		}
+	}
+}
+
+var (
+	wikitextCommentRegex = regexp.MustCompile(`(?s)<!--.*?-->`)
+	// tags whose contents are not prose
+	wikitextDroppedTags  = []string{"ref", "math", "chem", "gallery", "syntaxhighlight", "source", "code", "pre", "timeline", "score", "graph", "imagemap"}
+	wikitextExternalLink = regexp.MustCompile(`\[(?:[a-z]+:)?//[^\s\]]*\s*([^\]]*)\]`)
+	wikitextTagRegex     = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	wikitextQuoteRegex   = regexp.MustCompile(`'{2,}`)
+	wikitextHeadingRegex = regexp.MustCompile(`(?m)^=+|=+\s*$`)
+	wikitextMagicRegex   = regexp.MustCompile(`__[A-Z]+__`)
+)
+
+var wikitextDroppedTagRegexes = func() (regexes []*regexp.Regexp) {
+	for _, tag := range wikitextDroppedTags {
+		regexes = append(regexes,
+			regexp.MustCompile(fmt.Sprintf(`(?is)<%s\b[^>]*/>`, tag)),
+			regexp.MustCompile(fmt.Sprintf(`(?is)<%s\b[^>]*>.*?</%s\s*>`, tag, tag)))
+	}
+	return
+}()
+
+// Strips wikitext markup down to (approximately) the prose of the article: templates, tables,
+// references, comments, and file/category/interlanguage links are removed entirely, internal and
+// external links are replaced by their labels, and formatting markup is dropped
+func StripWikitext(text string) string {
+	text = wikitextCommentRegex.ReplaceAllString(text, "")
+	for _, regex := range wikitextDroppedTagRegexes {
+		text = regex.ReplaceAllString(text, "")
+	}
+	text = replaceNested(text, "{{", "}}", func(string) string { return "" })
+	text = replaceNested(text, "{|", "|}", func(string) string { return "" })
+	text = replaceNested(text, "[[", "]]", wikiLinkText)
+	text = wikitextExternalLink.ReplaceAllString(text, "$1")
+	text = wikitextTagRegex.ReplaceAllString(text, "")
+	text = wikitextQuoteRegex.ReplaceAllString(text, "")
+	text = wikitextHeadingRegex.ReplaceAllString(text, "")
+	text = wikitextMagicRegex.ReplaceAllString(text, "")
+	return html.UnescapeString(text)
+}
+
+// Prefixes of links which are not shown in the text of the page: embedded files, categories, and
+// links to the same article in other languages
+var wikiHiddenLinkPrefixes = setOf(
+	"file", "image", "category",
+	"en", "simple", "de", "fr", "es", "it", "nl", "pl", "pt", "ru", "ja", "zh", "sv", "uk", "vi", "ar",
+	"fa", "ca", "no", "nn", "fi", "cs", "hu", "ko", "id", "tr", "ro", "he", "da", "sr", "sh", "bg", "el",
+	"th", "hi", "bn", "ur", "ta", "ms", "eo", "eu", "et", "sk", "sl", "lt", "lv", "hr", "gl", "la", "ceb",
+	"war", "arz", "uz", "hy", "az", "be", "ka", "kk", "mk", "tl", "cy", "af", "sq", "is", "ga", "sw",
+)
+
+// Prefixes of links into other namespaces and wikis, which are shown by their label
+var wikiNamespaceLinkPrefixes = setOf(
+	"media", "template", "wikipedia", "wp", "project", "help", "portal", "user", "talk", "special",
+	"module", "draft", "mediawiki", "wikt", "wiktionary", "commons", "meta", "wikiquote", "q",
+	"wikisource", "s", "wikibooks", "b", "wikinews", "n", "wikiversity", "v", "wikivoyage", "voy",
+	"wikispecies", "species", "wikidata", "d",
+)
+
+func setOf(values ...string) map[string]bool {
+	set := map[string]bool{}
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+// Visible text of the contents of an internal link, e.g. "target|label" => "label", "target" => "target".
+// Files, categories and interlanguage links have no text, and links into other namespaces or wikis
+// only have the text of their label. Other targets containing colons are articles, e.g. "Batman: Year One"
+func wikiLinkText(inner string) string {
+	inner = replaceNested(inner, "[[", "]]", wikiLinkText)
+	target, label, hasLabel := strings.Cut(inner, "|")
+	// a leading colon makes a file, category or interlanguage link show as a normal link
+	name, shown := strings.CutPrefix(strings.TrimSpace(target), ":")
+	if shown {
+		target = name
+	}
+	if prefix, _, ok := strings.Cut(name, ":"); ok {
+		prefix = strings.ToLower(strings.TrimSpace(prefix))
+		if wikiHiddenLinkPrefixes[prefix] && !shown {
+			return ""
+		}
+		if wikiHiddenLinkPrefixes[prefix] || wikiNamespaceLinkPrefixes[prefix] {
+			return label
+		}
+	}
+	if hasLabel {
+		return label
+	}
+	return target
+}
+
+// Replaces every outermost balanced open...close span of s with replace(contents of the span).
+// Unbalanced openings are left as-is
+func replaceNested(s string, open string, close string, replace func(inner string) string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, open)
+		if start < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		b.WriteString(s[:start])
+
+		depth, end := 0, -1
+		for i := start; i < len(s); {
+			if strings.HasPrefix(s[i:], open) {
+				depth++
+				i += len(open)
+			} else if strings.HasPrefix(s[i:], close) {
+				depth--
+				i += len(close)
+				if depth == 0 {
+					end = i
+					break
+				}
+			} else {
+				i++
+			}
+		}
+		if end < 0 {
+			b.WriteString(open)
+			s = s[start+len(open):]
+			continue
+		}
+		b.WriteString(replace(s[start+len(open) : end-len(close)]))
+		s = s[end:]
+	}
+}
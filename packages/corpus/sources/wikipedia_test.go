@@ -0,0 +1,80 @@
+package sources
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseWikipediaFileSkipsRedirectsAndOtherNamespaces(t *testing.T) {
+	titles := []string{}
+	for page, err := range parseWikipediaFile(context.Background(), "testdata/pages.xml.bz2") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		titles = append(titles, page.Title)
+	}
+	if got, want := strings.Join(titles, ","), "Apple,Banana"; got != want {
+		t.Errorf("parsed pages %q, want %q", got, want)
+	}
+}
+
+func TestParseWikipediaFileText(t *testing.T) {
+	for page, err := range parseWikipediaFile(context.Background(), "testdata/pages.xml.bz2") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := StripWikitext(page.Revision.Text)
+		want := "An apple is a fruit that grows on trees."
+		if got != want {
+			t.Errorf("stripped text of %s is %q, want %q", page.Title, got, want)
+		}
+		break
+	}
+}
+
+func TestParseWikipediaFileStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var last error
+	for _, err := range parseWikipediaFile(ctx, "testdata/pages.xml.bz2") {
+		last = err
+	}
+	if last != context.Canceled {
+		t.Errorf("got error %v, want %v", last, context.Canceled)
+	}
+}
+
+func TestStripWikitext(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"template", "Paris{{efn|the capital}} is big", "Paris is big"},
+		{"nested templates", "Paris{{lang|fr|{{IPA|paʁi}}}} is big", "Paris is big"},
+		{"link", "the [[river]] flows", "the river flows"},
+		{"labelled link", "the [[Seine River|Seine]] flows", "the Seine flows"},
+		{"nested links", "[[File:Paris.jpg|thumb|The [[Eiffel Tower]] at night]]Paris", "Paris"},
+		{"link in label", "see [[Paris|the [[capital]] city]]", "see the capital city"},
+		{"category", "Paris[[Category:Cities in France]]", "Paris"},
+		{"lowercase namespace", "Paris[[category:Cities in France]][[image:Paris.png|thumb|A city]]", "Paris"},
+		{"interlanguage link", "Paris[[fr:Paris]][[de:Paris]]", "Paris"},
+		{"shown category link", "see [[:Category:Cities in France|cities]]", "see cities"},
+		{"namespace link", "see [[wikt:city|the definition]] and [[Help:Contents]]", "see the definition and "},
+		{"article with a colon", "[[Batman: The Killing Joke]] and [[Star Wars: Episode IV|Star Wars]] came out", "Batman: The Killing Joke and Star Wars came out"},
+		{"ref", "Paris is big.<ref name=\"size\">{{cite book|title=Paris}}</ref> It is old.", "Paris is big. It is old."},
+		{"self-closing ref", "Paris is big.<ref name=\"size\" /> It is old.", "Paris is big. It is old."},
+		{"table", "Paris\n{| class=\"wikitable\"\n|-\n! Year !! People\n|-\n| 2020 || {{formatnum:2145906}}\n|}\nis big", "Paris\n\nis big"},
+		{"external link", "see [https://paris.fr the city site]", "see the city site"},
+		{"formatting", "'''Paris''' is ''big''", "Paris is big"},
+		{"heading", "== History ==", " History "},
+		{"comment", "Paris<!-- not London --> is big", "Paris is big"},
+		{"entities", "Paris &amp; Lyon", "Paris & Lyon"},
+	}
+	for _, c := range cases {
+		if got := StripWikitext(c.text); got != c.want {
+			t.Errorf("%s: StripWikitext(%q) = %q, want %q", c.name, c.text, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package sources
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/digitaltembo/motli/packages/corpus/utils"
+)
+
+type WordList string
+
+const (
+	// Collins Scrabble Words 2021, the tournament list used outside of North America (also called SOWPODS)
+	WordList_Csw21 = "csw21"
+	// NASPA School Word List 2023, the school subset of the North American tournament list (NWL2023)
+	WordList_Nswl2023 = "nswl2023"
+)
+
+var wordListFiles = map[WordList]string{
+	"csw21":    "https://raw.githubusercontent.com/scrabblewords/scrabblewords/main/words/British/CSW21.txt",
+	"nswl2023": "https://raw.githubusercontent.com/scrabblewords/scrabblewords/main/words/North-American/NSWL2023.txt",
+}
+
+// Downloads a known word list into the data directory, as a newline-delimited text file
+func DownloadWordList(ctx context.Context, list WordList) (string, error) {
+	url, ok := wordListFiles[list]
+	if !ok {
+		return "", fmt.Errorf("invalid word list to download: %s", list)
+	}
+	target, err := utils.WordListFile(string(list))
+	if err != nil {
+		return "", err
+	}
+	if !utils.FileExists(target) {
+		err = utils.DownloadFile(ctx, target, url)
+		if err != nil {
+			return "", err
+		}
+	}
+	return target, nil
+}
+
+// Downloads either a wikiextract dictionary, a wikipedia dump or a word list, whichever the name refers to
+func Download(ctx context.Context, name string) (string, error) {
+	if _, ok := wordListFiles[WordList(name)]; ok {
+		return DownloadWordList(ctx, WordList(name))
+	}
+	if _, ok := wikipediaFiles[WikipediaLanguage(name)]; ok {
+		return DownloadWikipedia(ctx, WikipediaLanguage(name))
+	}
+	return DownloadWikiExtract(ctx, WikiExtractLanguage(name))
+}
+
+type wordListWordSource struct {
+	words map[string]*Word
+}
+
+// Loads a WordSource from a newline-delimited word list file, which may be gzipped if the name
+// ends in ".gz". Only the first whitespace-separated field of a line is used, so lists which include
+// definitions after the word are supported, and empty lines or lines starting with "#" are skipped
+func NewWordListWordSource(path string) (WordSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var contents io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		contents = gz
+	}
+
+	w := wordListWordSource{words: map[string]*Word{}}
+	scanner := bufio.NewScanner(contents)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		word := strings.ToLower(fields[0])
+		if _, ok := w.words[word]; !ok {
+			w.words[word] = &Word{Word: word}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(os.Stderr, "Finished reading word list %s, %d words\n", path, len(w.words))
+	return &w, nil
+}
+
+// Downloads (if necessary) and loads a known word list
+func newKnownWordListWordSource(ctx context.Context, list WordList) (WordSource, error) {
+	file, err := DownloadWordList(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+	return NewWordListWordSource(file)
+}
+
+// Word lists are all currently English
+func (w *wordListWordSource) Alphabet() string {
+	return englishAlphabet
+}
+
+// Word lists don't have parts of speech or other categories
+func (w *wordListWordSource) GetCategory(catId int) string {
+	return ""
+}
+
+func (w *wordListWordSource) GetWord(s string) *Word {
+	word, ok := w.words[strings.ToLower(s)]
+	if !ok {
+		return nil
+	}
+	return word
+}
+
+func (w *wordListWordSource) GetWordList() []*Word {
+	return slices.Collect(maps.Values(w.words))
+}
@@ -1,8 +1,12 @@
 package sources
 
 import (
+	"context"
 	"fmt"
 	"regexp"
+	"strings"
+
+	"github.com/digitaltembo/motli/packages/corpus/utils"
 )
 
 type WordSourceId string
@@ -17,6 +21,14 @@ const (
 	WordSourceId_WeEn = "we-en"
 	// All English words from the wikiextract English dictionary
 	WordSourceId_WeEnAll = "we-en-all"
+
+	// Words from the Collins Scrabble Words 2021 tournament list
+	WordSourceId_Csw21 = "csw21"
+	// Words from the NASPA School Word List 2023, the list of words allowed in North American school tournaments
+	WordSourceId_Nswl2023 = "nswl2023"
+
+	// Prefix for word sources loaded from an arbitrary word list file, e.g. "file:/path/to/words.txt.gz"
+	WordSourceIdPrefix_File = "file:"
 )
 
 type Word struct {
@@ -25,27 +37,68 @@ type Word struct {
 	Freq       int    `json:"freq"`
 }
 type WordSource interface {
+	Alphabet() string
 	GetCategory(catId int) string
 	GetWord(w string) *Word
 	GetWordList() []*Word
 }
 
-func GetWordSource(srcId WordSourceId) (WordSource, error) {
+func GetWordSource(ctx context.Context, srcId WordSourceId) (WordSource, error) {
+	if files, err := WordSourceFiles(srcId); err == nil {
+		utils.RecordInput(ctx, files...)
+	}
 	switch srcId {
 	case WordSourceId_WeSimpleEnAll:
-		return newWikiExtractWordSource(WikiExtractLanguage_SimpleEn)
+		return newWikiExtractWordSource(ctx, WikiExtractLanguage_SimpleEn)
 	case WordSourceId_WeEnAll:
-		return newWikiExtractWordSource(WikiExtractLanguage_En)
+		return newWikiExtractWordSource(ctx, WikiExtractLanguage_En)
+	case WordSourceId_Csw21:
+		return newKnownWordListWordSource(ctx, WordList_Csw21)
+	case WordSourceId_Nswl2023:
+		return newKnownWordListWordSource(ctx, WordList_Nswl2023)
 	default:
+		if path, ok := strings.CutPrefix(string(srcId), WordSourceIdPrefix_File); ok {
+			return NewWordListWordSource(path)
+		}
 		return nil, fmt.Errorf("unsupported word source")
 	}
 }
 
+// Files the word source is loaded from (which may not have been downloaded yet)
+func WordSourceFiles(srcId WordSourceId) ([]string, error) {
+	var file string
+	var err error
+	switch srcId {
+	case WordSourceId_WeSimpleEnAll:
+		file, err = utils.WikiExtractFile(WikiExtractLanguage_SimpleEn)
+	case WordSourceId_WeEnAll:
+		file, err = utils.WikiExtractFile(WikiExtractLanguage_En)
+	case WordSourceId_Csw21:
+		file, err = utils.WordListFile(WordList_Csw21)
+	case WordSourceId_Nswl2023:
+		file, err = utils.WordListFile(WordList_Nswl2023)
+	default:
+		path, ok := strings.CutPrefix(string(srcId), WordSourceIdPrefix_File)
+		if !ok {
+			return nil, fmt.Errorf("unsupported word source")
+		}
+		file = path
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []string{file}, nil
+}
+
 type filteredWordSource struct {
 	s      WordSource
 	filter func(*Word) bool
 }
 
+func (f *filteredWordSource) Alphabet() string {
+	return f.s.Alphabet()
+}
+
 func (f *filteredWordSource) GetCategory(catId int) string {
 	return f.s.GetCategory(catId)
 }
@@ -76,6 +129,50 @@ func FilterWordSource(source WordSource, filter func(*Word) bool) WordSource {
 	}
 }
 
+type frequencyWordSource struct {
+	s     WordSource
+	freqs map[string]int
+}
+
+func (f *frequencyWordSource) Alphabet() string {
+	return f.s.Alphabet()
+}
+
+func (f *frequencyWordSource) GetCategory(catId int) string {
+	return f.s.GetCategory(catId)
+}
+
+func (f *frequencyWordSource) GetWord(w string) *Word {
+	word := f.s.GetWord(w)
+	if word == nil {
+		return nil
+	}
+	return f.withFreq(word)
+}
+
+func (f *frequencyWordSource) GetWordList() (ret []*Word) {
+	for _, word := range f.s.GetWordList() {
+		ret = append(ret, f.withFreq(word))
+	}
+	return
+}
+
+// copy of the word with the Freq filled in, leaving the underlying source's word untouched
+func (f *frequencyWordSource) withFreq(word *Word) *Word {
+	withFreq := *word
+	withFreq.Freq = f.freqs[word.Word]
+	return &withFreq
+}
+
+// Decorates the word source so that every word's Freq is populated from the frequency table,
+// defaulting to 0 for words not in the table
+func WithFrequencies(source WordSource, freqs map[string]int) WordSource {
+	return &frequencyWordSource{
+		s:     source,
+		freqs: freqs,
+	}
+}
+
 var reasonableEnglishRegex *regexp.Regexp = nil
 
 // a simple filter for reasonable words to play,
@@ -0,0 +1,3 @@
+// Spelling Bee puzzles: seven letters, one of which is the center letter that every answer must
+// contain, with a pangram using all seven, and the scoring of their answers
+package spellingbee
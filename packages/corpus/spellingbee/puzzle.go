@@ -0,0 +1,111 @@
+package spellingbee
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Number of distinct letters of a puzzle
+const Letters = 7
+
+// Minimum number of letters of an answer
+const MinLength = 4
+
+// Bonus points for a pangram, which uses every letter of the puzzle
+const PangramBonus = 7
+
+// A Spelling Bee puzzle and every answer to it
+type Puzzle struct {
+	// The seven letters of the puzzle, alphabetically, including the center letter
+	Letters string `json:"letters"`
+	// Letter which every answer must contain
+	Center string `json:"center"`
+	// Every answer, alphabetically
+	Answers []string `json:"answers"`
+	// Answers using every letter of the puzzle
+	Pangrams []string `json:"pangrams"`
+	// Total points of every answer
+	Points int `json:"points"`
+}
+
+// Points for the answer to a puzzle of the letters: 1 for a four-letter answer, a point per letter
+// for longer answers, and a bonus of 7 for a pangram
+func Score(answer string, letters string) int {
+	length := utf8.RuneCountInString(answer)
+	points := length
+	if length == MinLength {
+		points = 1
+	}
+	if letterSet(answer) == letterSet(letters) {
+		points += PangramBonus
+	}
+	return points
+}
+
+// Generates every puzzle that can be made from the words: each set of seven letters used by some
+// word is a puzzle for each choice of center letter, and its answers are the words of at least 4
+// letters using only letters of the set, including the center
+func Generate(words []string) []Puzzle {
+	// words, grouped by the set of distinct letters they use
+	bySet := map[string][]string{}
+	for _, word := range words {
+		word = strings.ToLower(word)
+		if utf8.RuneCountInString(word) < MinLength {
+			continue
+		}
+		set := letterSet(word)
+		if utf8.RuneCountInString(set) <= Letters && !slices.Contains(bySet[set], word) {
+			bySet[set] = append(bySet[set], word)
+		}
+	}
+
+	puzzles := []Puzzle{}
+	for _, set := range slices.Sorted(maps.Keys(bySet)) {
+		letters := []rune(set)
+		if len(letters) != Letters {
+			continue
+		}
+		// words of each subset of the letters, where bit i of the subset is letters[i]
+		subsets := make([][]string, 1<<Letters)
+		for subset := 1; subset < len(subsets); subset++ {
+			subsets[subset] = bySet[subsetLetters(letters, subset)]
+		}
+		for c, center := range letters {
+			puzzle := Puzzle{Letters: set, Center: string(center), Answers: []string{}, Pangrams: []string{}}
+			for subset, answers := range subsets {
+				if subset&(1<<c) == 0 {
+					continue
+				}
+				for _, answer := range answers {
+					puzzle.Answers = append(puzzle.Answers, answer)
+					puzzle.Points += Score(answer, set)
+				}
+			}
+			slices.Sort(puzzle.Answers)
+			puzzle.Pangrams = slices.Clone(bySet[set])
+			slices.Sort(puzzle.Pangrams)
+			puzzles = append(puzzles, puzzle)
+		}
+	}
+	return puzzles
+}
+
+// The distinct letters of the word, alphabetically
+func letterSet(word string) string {
+	letters := []rune(strings.ToLower(word))
+	slices.Sort(letters)
+	return string(slices.Compact(letters))
+}
+
+// The letters of the subset, where bit i is set for each letters[i] it includes
+func subsetLetters(letters []rune, subset int) string {
+	included := []rune{}
+	for i, r := range letters {
+		if subset&(1<<i) != 0 {
+			included = append(included, r)
+		}
+	}
+	return string(included)
+}
@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// Exit codes of the corpus tool
+const (
+	ExitCode_Success = 0
+	// The command ran and failed
+	ExitCode_Failure = 1
+	// The command line was invalid, e.g. an unknown command or flag
+	ExitCode_Usage = 2
+	// The command was interrupted, e.g. with ctrl-c, following the shell convention of 128 + SIGINT
+	ExitCode_Interrupted = 130
+)
+
+// A subcommand of the corpus tool, e.g. "corpus analyze ngrams [language]"
+type Command struct {
+	// Words naming the command, e.g. "analyze ngrams"
+	Name string
+	// Names of the positional arguments, e.g. "language", all of which are required
+	Args []string
+	// One line description, shown when listing commands
+	Summary string
+	// Full description, shown in the help of the command
+	Description string
+	// Defines the flags of the command on the flag set, and returns the function running the
+	// command with the positional arguments once the flags are parsed. The context is cancelled
+	// when the tool is interrupted, and the command should stop promptly when it is
+	Setup func(flags *flag.FlagSet) func(ctx context.Context, args []string) error
+}
+
+// Every registered command, by name
+var commands = map[string]*Command{}
+
+// Adds a command to the corpus tool, typically from the init function of the package implementing it
+func RegisterCommand(command *Command) {
+	if _, ok := commands[command.Name]; ok {
+		panic(fmt.Sprintf("command %q registered twice", command.Name))
+	}
+	commands[command.Name] = command
+}
+
+// Every registered command, sorted by name
+func Commands() []*Command {
+	sorted := []*Command{}
+	for _, name := range slices.Sorted(maps.Keys(commands)) {
+		sorted = append(sorted, commands[name])
+	}
+	return sorted
+}
+
+// Runs the command named by the leading arguments (preferring the longest name, so "analyze ngrams"
+// over "analyze") with the rest of the arguments, where flags and positional arguments may be mixed.
+// Failures are printed to stderr, and the returned exit code is non-zero for them. An interrupt
+// (SIGINT) cancels the context of the command rather than killing the tool outright, so that it can
+// stop its work and clean up, and a second interrupt kills it as usual
+func RunCommand(args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		if len(args) > 1 {
+			return RunCommand(append(args[1:], "--help"))
+		}
+		printUsage(os.Stdout, "")
+		if len(args) == 0 {
+			return ExitCode_Usage
+		}
+		return ExitCode_Success
+	}
+
+	var command *Command
+	nameLength := 0
+	for i := len(args); i > 0 && command == nil; i-- {
+		if c, ok := commands[strings.Join(args[:i], " ")]; ok {
+			command, nameLength = c, i
+		}
+	}
+	if command == nil {
+		prefix := strings.Join(slices.DeleteFunc(slices.Clone(args), func(arg string) bool {
+			return strings.HasPrefix(arg, "-")
+		}), " ")
+		if !hasCommandsUnder(prefix) {
+			fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", prefix)
+		} else if slices.Contains(args, "-h") || slices.Contains(args, "--help") {
+			printUsage(os.Stdout, prefix)
+			return ExitCode_Success
+		}
+		printUsage(os.Stderr, prefix)
+		return ExitCode_Usage
+	}
+
+	flags := flag.NewFlagSet(command.Name, flag.ContinueOnError)
+	run := command.Setup(flags)
+	force := flags.Bool("force", false, "Rebuild every cached artifact the command uses, even if it is up to date")
+	flags.Usage = func() {
+		printCommandUsage(flags.Output(), command, flags)
+	}
+	positional := []string{}
+	rest := args[nameLength:]
+	for {
+		if err := flags.Parse(rest); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return ExitCode_Success
+			}
+			return ExitCode_Usage
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		positional = append(positional, flags.Arg(0))
+		rest = flags.Args()[1:]
+	}
+	if len(positional) != len(command.Args) {
+		fmt.Fprintf(os.Stderr, "Wrong number of arguments, expected %d but got %d\n\n", len(command.Args), len(positional))
+		printCommandUsage(os.Stderr, command, flags)
+		return ExitCode_Usage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *force {
+		ctx = WithForce(ctx)
+	}
+	go func() {
+		// restore the default behavior, so that a second interrupt kills the tool
+		<-ctx.Done()
+		stop()
+	}()
+	if err := run(ctx, positional); err != nil {
+		if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+			fmt.Fprintf(os.Stderr, "corpus %s: interrupted\n", command.Name)
+			return ExitCode_Interrupted
+		}
+		fmt.Fprintf(os.Stderr, "corpus %s: %s\n", command.Name, err.Error())
+		return ExitCode_Failure
+	}
+	return ExitCode_Success
+}
+
+// Whether there are commands whose names start with the words of the prefix
+func hasCommandsUnder(prefix string) bool {
+	for name := range commands {
+		if strings.HasPrefix(name+" ", prefix+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// Usage line of the command, e.g. "corpus playability [flags] <tiles file>"
+func commandUsage(command *Command) string {
+	usage := "corpus " + command.Name + " [flags]"
+	for _, arg := range command.Args {
+		usage += " <" + arg + ">"
+	}
+	return usage
+}
+
+// Prints the commands starting with the words of the prefix (or every command, if it is empty)
+func printUsage(w io.Writer, prefix string) {
+	fmt.Fprintf(w, "Usage:\n\n\tcorpus <command> [flags] [arguments]\n\nThe commands are:\n\n")
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, command := range Commands() {
+		if prefix == "" || !hasCommandsUnder(prefix) || strings.HasPrefix(command.Name+" ", prefix+" ") {
+			fmt.Fprintf(tw, "\t%s\t%s\n", command.Name, command.Summary)
+		}
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\nUse \"corpus help <command>\" for more information about a command.\n")
+}
+
+// Prints the usage, description and flags of the command
+func printCommandUsage(w io.Writer, command *Command, flags *flag.FlagSet) {
+	fmt.Fprintf(w, "Usage:\n\n\t%s\n\n", commandUsage(command))
+	for _, line := range strings.Split(command.Description, "\n") {
+		fmt.Fprintf(w, "%s\n", line)
+	}
+	hasFlags := false
+	flags.VisitAll(func(*flag.Flag) { hasFlags = true })
+	if hasFlags {
+		fmt.Fprintf(w, "\nThe flags are:\n\n")
+		flags.SetOutput(w)
+		flags.PrintDefaults()
+	}
+}
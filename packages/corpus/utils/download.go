@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"time"
@@ -9,13 +10,17 @@ import (
 )
 
 // Downloads a file from the provided url to the filepath, using
-// the grab library, and printing progress
-func DownloadFile(filepath string, url string) (err error) {
+// the grab library, and printing progress. The partially downloaded file is
+// removed if the download fails or the context is cancelled
+func DownloadFile(ctx context.Context, filepath string, url string) (err error) {
 	fmt.Printf("Downloading file from %s to %s", url, filepath)
 
 	client := grab.NewClient()
 	req, err := grab.NewRequest(filepath, url)
-	resp := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp := client.Do(req.WithContext(ctx))
 
 	t := time.NewTicker(time.Second)
 	defer t.Stop()
@@ -30,6 +35,7 @@ func DownloadFile(filepath string, url string) (err error) {
 
 		case <-resp.Done:
 			if err := resp.Err(); err != nil {
+				os.Remove(filepath)
 				return err
 			}
 			return nil
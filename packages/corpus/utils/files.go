@@ -6,6 +6,7 @@ import (
 	"os"
 	"path"
 	"runtime"
+	"strings"
 )
 
 // Directory of all output files and stored input files for processing steps
@@ -27,14 +28,43 @@ func WikiExtractFile(language string) (string, error) {
 	return path.Join(data, fmt.Sprintf("%s.jsonl.gz", language)), nil
 }
 
-// Path to the csv file of analysis of ngrams of size ngram in the provided language
-func NgramFile(language string, ngram int) (string, error) {
+// Path to the bz2-compressed MediaWiki XML dump of the provided wikipedia language
+func WikipediaFile(language string) (string, error) {
 	data, err := DataDir()
 	if err != nil {
 		return "", err
 	}
+	return path.Join(data, fmt.Sprintf("%s.xml.bz2", language)), nil
+}
+
+// Path to the newline-delimited text file of the named word list
+func WordListFile(name string) (string, error) {
+	data, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(data, fmt.Sprintf("%s.txt", name)), nil
+}
 
-	return path.Join(data, fmt.Sprintf("%s-%dgram.csv", language, ngram)), nil
+// Path to the csv file of analysis of ngrams of the sizes described by ngrams (e.g. "1" or "1-5")
+// for the named analysis
+func NgramFile(analysis string, ngrams string) (string, error) {
+	data, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(data, fmt.Sprintf("%s-%sgram.csv", fileSafe(analysis), ngrams)), nil
+}
+
+// Path to the csv file of the frequency of usage of the words of a word source in the provided language
+func FrequencyFile(language string, words string) (string, error) {
+	data, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(data, fmt.Sprintf("%s-%s-freq.csv", fileSafe(language), fileSafe(words))), nil
 }
 
 // Path to the json file of the suggested tile distribution in the provided language
@@ -47,6 +77,131 @@ func TileFile(language string, tileCount int) (string, error) {
 	return path.Join(data, fmt.Sprintf("%s-%dtiles.json", language, tileCount)), nil
 }
 
+// Path to the json file of the point values of tiles for the named scoring
+func ScoreFile(scoring string) (string, error) {
+	data, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(data, fmt.Sprintf("%s-scores.json", fileSafe(scoring))), nil
+}
+
+// Path to the json file of the results of the named playability simulation
+func PlayabilityFile(simulation string) (string, error) {
+	data, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(data, fmt.Sprintf("%s-playability.json", fileSafe(simulation))), nil
+}
+
+// Path to the json file of the named optimized distribution of tiles
+func OptimizedTilesFile(optimization string) (string, error) {
+	data, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(data, fmt.Sprintf("%s-optimized.json", fileSafe(optimization))), nil
+}
+
+// Path to the binary compiled lexicon (DAWG and GADDAG) of the provided word source
+func LexiconFile(words string) (string, error) {
+	data, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(data, fmt.Sprintf("%s.lex", fileSafe(words))), nil
+}
+
+// Path to the json file of the named set of solved Boggle boards
+func BoggleFile(boards string) (string, error) {
+	data, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(data, fmt.Sprintf("%s-boggle.json", fileSafe(boards))), nil
+}
+
+// Path to the json file of the named set of Boggle dice
+func DiceFile(dice string) (string, error) {
+	data, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(data, fmt.Sprintf("%s-dice.json", fileSafe(dice))), nil
+}
+
+// Path to the json file of the named Wordle answer and guess lists
+func WordleFile(lists string) (string, error) {
+	data, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(data, fmt.Sprintf("%s-wordle.json", fileSafe(lists))), nil
+}
+
+// Path to the json file of the named difficulty of Wordle answers
+func WordleDifficultyFile(difficulty string) (string, error) {
+	data, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(data, fmt.Sprintf("%s-wordle-difficulty.json", fileSafe(difficulty))), nil
+}
+
+// Path to the json file of the named set of Spelling Bee puzzles
+func SpellingBeeFile(puzzles string) (string, error) {
+	data, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(data, fmt.Sprintf("%s-spelling-bee.json", fileSafe(puzzles))), nil
+}
+
+// Path to the json file of the named word search puzzle
+func WordSearchFile(puzzle string) (string, error) {
+	data, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(data, fmt.Sprintf("%s-wordsearch.json", fileSafe(puzzle))), nil
+}
+
+// Path to the json file of the named report of self-play games
+func SelfPlayFile(games string) (string, error) {
+	data, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(data, fmt.Sprintf("%s-selfplay.json", fileSafe(games))), nil
+}
+
+// Path to the json manifest of the artifacts in the data directory and the inputs they were built from
+func ManifestFile() (string, error) {
+	data, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(data, "manifest.json"), nil
+}
+
+// Makes a source name (which may be a path, e.g. "file:/path/to/words.txt") usable as part of a file name
+func fileSafe(name string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(name)
+}
+
 // helper to determine whether file exists
 func FileExists(file string) bool {
 	if _, err := os.Stat(file); errors.Is(err, os.ErrNotExist) {
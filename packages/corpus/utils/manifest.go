@@ -0,0 +1,339 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Version of the corpus tool, recorded with every artifact it builds. Bump it whenever a change alters
+// the contents of an artifact, so that the artifacts built by older versions are rebuilt
+const ToolVersion = "1"
+
+// Record of the artifacts in the data directory - outputs of processes which are cached and reused,
+// such as ngram analyses - along with what they were built from, so that they can be rebuilt when
+// any of it changes
+type Manifest struct {
+	// Artifacts by file, relative to the data directory
+	Artifacts map[string]*Artifact `json:"artifacts"`
+	// Hashes of the artifacts and their inputs by file, cached by their size and modification time
+	// so that large downloads are only hashed once
+	Files map[string]*FileHash `json:"files"`
+}
+
+// Build of an artifact
+type Artifact struct {
+	// Parameters of the process which built the artifact, e.g. its language source or ngram sizes
+	Params map[string]string `json:"params"`
+	// Hashes of the files read to build the artifact (both source files and upstream artifacts), by file
+	Inputs map[string]string `json:"inputs"`
+	// Version of the corpus tool which built the artifact
+	ToolVersion string `json:"toolVersion"`
+	// Hash of the artifact as it was built
+	Hash string `json:"hash"`
+	// When the artifact was built
+	Built time.Time `json:"built"`
+}
+
+// Hash of the contents of a file, valid while it has the same size and modification time
+type FileHash struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Hash    string    `json:"hash"`
+}
+
+// The manifest loaded by this run of the tool, and the artifacts which have been built by it
+var manifestState struct {
+	sync.Mutex
+	manifest *Manifest
+	built    map[string]bool
+	// whether hashes have been cached since the manifest was last written
+	dirty bool
+}
+
+type inputsKey struct{}
+type forceKey struct{}
+
+// Files read while building an artifact
+type inputs struct {
+	sync.Mutex
+	files map[string]bool
+}
+
+// Context in which every artifact is rebuilt, whether or not it is up to date
+func WithForce(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceKey{}, true)
+}
+
+// Records that the files are being read by the artifact being built in the context (if any), so that
+// it is rebuilt when they change
+func RecordInput(ctx context.Context, files ...string) {
+	in, ok := ctx.Value(inputsKey{}).(*inputs)
+	if !ok {
+		return
+	}
+	in.Lock()
+	defer in.Unlock()
+	for _, file := range files {
+		in.files[manifestKey(file)] = true
+	}
+}
+
+// Builds the artifact file with build, unless it was already built with the same parameters by the
+// same version of the tool, and neither it nor anything it was built from (recursively) has changed
+// since. Files read by build should be recorded with RecordInput on the context it is passed, which
+// is done by the sources and by any upstream artifacts. Returns whether the artifact was built
+func BuildArtifact(ctx context.Context, file string, params map[string]string, build func(ctx context.Context) error) (bool, error) {
+	key := manifestKey(file)
+	// the artifact is itself an input of any artifact being built
+	RecordInput(ctx, file)
+
+	manifestState.Lock()
+	manifest, err := loadManifest()
+	if err != nil {
+		manifestState.Unlock()
+		return false, err
+	}
+	reason := ""
+	if force, _ := ctx.Value(forceKey{}).(bool); force && !manifestState.built[key] {
+		reason = "forced"
+	} else {
+		reason = manifest.staleness(key, params, map[string]bool{})
+	}
+	manifestState.Unlock()
+	if reason == "" {
+		return false, saveManifest()
+	}
+	fmt.Fprintf(os.Stderr, "Building %s (%s)\n", key, reason)
+
+	in := &inputs{files: map[string]bool{}}
+	if err := build(context.WithValue(ctx, inputsKey{}, in)); err != nil {
+		return false, err
+	}
+
+	manifestState.Lock()
+	defer manifestState.Unlock()
+	artifact := Artifact{
+		Params:      params,
+		Inputs:      map[string]string{},
+		ToolVersion: ToolVersion,
+		Built:       time.Now().UTC(),
+	}
+	for input := range in.files {
+		if input == key {
+			continue
+		}
+		if artifact.Inputs[input], err = manifest.hash(input); err != nil {
+			return false, err
+		}
+	}
+	if artifact.Hash, err = manifest.hash(key); err != nil {
+		return false, err
+	}
+	manifest.Artifacts[key] = &artifact
+	manifestState.built[key] = true
+	return true, writeManifest(manifest)
+}
+
+// Loads the manifest of the data directory, which is empty if it has not been written yet
+func ReadManifest() (*Manifest, error) {
+	manifestState.Lock()
+	defer manifestState.Unlock()
+	return loadManifest()
+}
+
+// Why the artifact needs to be rebuilt, e.g. "input we-en.jsonl.gz changed", or "" if it is up to date.
+// Any parameters the artifact was built with are accepted
+func (m *Manifest) Staleness(file string) string {
+	manifestState.Lock()
+	defer manifestState.Unlock()
+	return m.staleness(manifestKey(file), nil, map[string]bool{})
+}
+
+// The files of the artifacts which are not read by any other artifact, sorted
+func (m *Manifest) Roots() []string {
+	read := map[string]bool{}
+	for _, artifact := range m.Artifacts {
+		for input := range artifact.Inputs {
+			read[input] = true
+		}
+	}
+	return slices.Sorted(func(yield func(string) bool) {
+		for key := range m.Artifacts {
+			if !read[key] && !yield(key) {
+				return
+			}
+		}
+	})
+}
+
+// Why the artifact needs to be rebuilt, or "" if it is up to date. Params are only compared if they
+// are non-nil, and checked holds the artifacts already found to be up to date
+func (m *Manifest) staleness(key string, params map[string]string, checked map[string]bool) string {
+	if checked[key] || manifestState.built[key] {
+		return ""
+	}
+	artifact, ok := m.Artifacts[key]
+	if !ok {
+		if !FileExists(manifestPath(key)) {
+			return "missing"
+		}
+		return "not in the manifest"
+	}
+	if artifact.ToolVersion != ToolVersion {
+		return fmt.Sprintf("built by version %s of the tool", artifact.ToolVersion)
+	}
+	if params != nil && !maps.Equal(artifact.Params, params) {
+		return "parameters changed"
+	}
+	if hash, err := m.hash(key); err != nil {
+		return "missing"
+	} else if hash != artifact.Hash {
+		return "modified since it was built"
+	}
+	for _, input := range slices.Sorted(maps.Keys(artifact.Inputs)) {
+		if _, ok := m.Artifacts[input]; ok {
+			if reason := m.staleness(input, nil, checked); reason != "" {
+				return fmt.Sprintf("input %s is stale: %s", input, reason)
+			}
+		}
+		if hash, err := m.hash(input); err != nil {
+			return fmt.Sprintf("input %s is missing", input)
+		} else if hash != artifact.Inputs[input] {
+			return fmt.Sprintf("input %s changed", input)
+		}
+	}
+	checked[key] = true
+	return ""
+}
+
+// Hash of the file, reusing the cached hash if the file has the same size and modification time
+func (m *Manifest) hash(key string) (string, error) {
+	file := manifestPath(key)
+	info, err := os.Stat(file)
+	if err != nil {
+		return "", err
+	}
+	cached, ok := m.Files[key]
+	if ok && cached.Size == info.Size() && cached.ModTime.Equal(info.ModTime()) {
+		return cached.Hash, nil
+	}
+
+	if info.Size() > 1<<30 {
+		fmt.Fprintf(os.Stderr, "Hashing %s (%d MB)\n", key, info.Size()>>20)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
+	}
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	m.Files[key] = &FileHash{Size: info.Size(), ModTime: info.ModTime(), Hash: hash}
+	if m == manifestState.manifest {
+		manifestState.dirty = true
+	}
+	return hash, nil
+}
+
+// Loads the manifest if it hasn't been yet in this run, with the state locked
+func loadManifest() (*Manifest, error) {
+	if manifestState.manifest != nil {
+		return manifestState.manifest, nil
+	}
+	manifest := Manifest{Artifacts: map[string]*Artifact{}, Files: map[string]*FileHash{}}
+	file, err := ManifestFile()
+	if err != nil {
+		return nil, err
+	}
+	contents, err := os.ReadFile(file)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	} else if err == nil {
+		if err := json.Unmarshal(contents, &manifest); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", file, err)
+		}
+	}
+	manifestState.manifest = &manifest
+	manifestState.built = map[string]bool{}
+	return &manifest, nil
+}
+
+// Saves the manifest if hashes have been cached since it was last written
+func saveManifest() error {
+	manifestState.Lock()
+	defer manifestState.Unlock()
+	if !manifestState.dirty {
+		return nil
+	}
+	return writeManifest(manifestState.manifest)
+}
+
+// Writes the manifest to a temporary file which then replaces the manifest, so that it is never
+// left half-written
+func writeManifest(manifest *Manifest) error {
+	file, err := ManifestFile()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(path.Dir(file), 0755); err != nil {
+		return err
+	}
+	contents, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(file+".tmp", contents, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(file+".tmp", file); err != nil {
+		return err
+	}
+	if manifest == manifestState.manifest {
+		manifestState.dirty = false
+	}
+	return nil
+}
+
+// Name of the file in the manifest, relative to the data directory if it is within it
+func manifestKey(file string) string {
+	data, err := DataDir()
+	if err != nil {
+		return file
+	}
+	absolute, err := filepath.Abs(file)
+	if err != nil {
+		return file
+	}
+	if relative, err := filepath.Rel(data, absolute); err == nil && !strings.HasPrefix(relative, "..") {
+		return relative
+	}
+	return absolute
+}
+
+// Path of the file with the name in the manifest
+func manifestPath(key string) string {
+	if filepath.IsAbs(key) {
+		return key
+	}
+	data, err := DataDir()
+	if err != nil {
+		return key
+	}
+	return path.Join(data, key)
+}
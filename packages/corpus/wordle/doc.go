@@ -0,0 +1,3 @@
+// Feedback for guesses in Wordle and an entropy-maximizing solver, used to gauge how hard
+// each answer is to find and which words make the best openers
+package wordle
@@ -0,0 +1,101 @@
+package wordle
+
+import "strings"
+
+// Color of a single letter of a guess
+type Mark int
+
+const (
+	// The letter is not in the answer (or not any more times than it is marked elsewhere)
+	Mark_Gray Mark = iota
+	// The letter is in the answer, but in a different position
+	Mark_Yellow
+	// The letter is in the answer in this position
+	Mark_Green
+)
+
+// Longest words the solver supports, as every possible feedback of a word is counted in a table
+const MaxLength = 12
+
+// The marks of every letter of a guess, packed into a base-3 number where the mark of the ith
+// letter is the ith digit, so every feedback of a word of length n is less than 3^n
+type Feedback int
+
+// Feedback for the guess against the answer, which must have the same number of letters. Greens are
+// marked first, and then each remaining letter of the guess is yellow only while there are unmarked
+// copies of it left in the answer, so guessing "speed" against "abide" marks just the first "e" yellow
+func Score(guess string, answer string) Feedback {
+	return score([]rune(guess), []rune(answer))
+}
+
+func score(guess []rune, answer []rune) Feedback {
+	var marks [MaxLength]Mark
+	// letters of the answer not matched by a green
+	var unmatched [MaxLength]rune
+	remaining := 0
+	for i, r := range answer {
+		if guess[i] == r {
+			marks[i] = Mark_Green
+		} else {
+			unmatched[remaining] = r
+			remaining++
+		}
+	}
+	for i, r := range guess {
+		if marks[i] == Mark_Green {
+			continue
+		}
+		for j := range remaining {
+			if unmatched[j] == r {
+				marks[i] = Mark_Yellow
+				remaining--
+				unmatched[j] = unmatched[remaining]
+				break
+			}
+		}
+	}
+	return pack(marks[:len(guess)])
+}
+
+// Packs the marks into a Feedback
+func pack(marks []Mark) Feedback {
+	f := Feedback(0)
+	for i := len(marks) - 1; i >= 0; i-- {
+		f = f*3 + Feedback(marks[i])
+	}
+	return f
+}
+
+// The mark of each of the length letters of the guess
+func (f Feedback) Marks(length int) []Mark {
+	marks := make([]Mark, length)
+	for i := range marks {
+		marks[i] = Mark(f % 3)
+		f /= 3
+	}
+	return marks
+}
+
+// Whether every one of the length letters of the guess is green
+func (f Feedback) Solved(length int) bool {
+	return f == patterns(length)-1
+}
+
+// The marks of the length letters of the guess as a string like "g-y--", with "g" for green,
+// "y" for yellow and "-" for gray
+func (f Feedback) Pattern(length int) string {
+	var s strings.Builder
+	for _, mark := range f.Marks(length) {
+		s.WriteByte("-yg"[mark])
+	}
+	return s.String()
+}
+
+// Number of possible feedbacks for a word of the length, 3^length
+func patterns(length int) Feedback {
+	n := Feedback(1)
+	for range length {
+		n *= 3
+	}
+	return n
+}
@@ -0,0 +1,46 @@
+package wordle
+
+import "testing"
+
+func TestScore(t *testing.T) {
+	cases := []struct {
+		guess   string
+		answer  string
+		pattern string
+	}{
+		{"crane", "crane", "ggggg"},
+		{"slate", "crane", "--g-g"},
+		// only as many yellows as there are unmatched copies in the answer
+		{"speed", "abide", "--y-y"},
+		// a green takes the only copy, even when a gray copy comes first
+		{"geese", "those", "---gg"},
+		{"eerie", "those", "----g"},
+		// a repeated letter in both is yellow once per copy
+		{"abbey", "babes", "yygg-"},
+		{"trace", "react", "yyggy"},
+	}
+	for _, c := range cases {
+		if got := Score(c.guess, c.answer).Pattern(5); got != c.pattern {
+			t.Errorf("Score(%q, %q) = %q, want %q", c.guess, c.answer, got, c.pattern)
+		}
+	}
+}
+
+func TestFeedbackMarks(t *testing.T) {
+	marks := []Mark{Mark_Green, Mark_Gray, Mark_Yellow, Mark_Gray, Mark_Green}
+	f := pack(marks)
+	if got := f.Pattern(len(marks)); got != "g-y-g" {
+		t.Errorf("packed pattern is %q, want %q", got, "g-y-g")
+	}
+	for i, mark := range f.Marks(len(marks)) {
+		if mark != marks[i] {
+			t.Errorf("mark %d is %d, want %d", i, mark, marks[i])
+		}
+	}
+	if f.Solved(len(marks)) {
+		t.Errorf("%q is solved", f.Pattern(len(marks)))
+	}
+	if !Score("crane", "crane").Solved(5) {
+		t.Errorf("guessing the answer is not solved")
+	}
+}
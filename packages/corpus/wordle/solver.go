@@ -0,0 +1,189 @@
+package wordle
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Solver which always guesses the allowed word that maximizes the expected information (the
+// Shannon entropy of the feedback) over the answers still consistent with every feedback so far.
+// A solver caches its decisions between games, so is not safe for concurrent use
+type Solver struct {
+	length  int
+	guesses []string
+	runes   [][]rune
+	// index into the guesses of each answer, which are always allowed guesses
+	answers []int
+	// best guess after each sequence of feedbacks, as the solver is deterministic
+	best map[string]int
+	// count of each feedback, and which feedbacks were counted, reused between evaluations
+	counts  []int
+	counted []Feedback
+}
+
+// A guess along with the information it is expected to give
+type Opener struct {
+	Word    string  `json:"word"`
+	Entropy float64 `json:"entropy"`
+}
+
+// A game played by the solver
+type Game struct {
+	Answer string `json:"answer"`
+	// Every guess made, ending with the answer
+	Guesses []string `json:"guesses"`
+	// Feedback of each guess, as patterns like "g-y--"
+	Feedback []string `json:"feedback"`
+}
+
+// Creates a solver that guesses from the allowed guesses, considering the answers to be the only possible
+// solutions. Answers are added to the guesses if missing, and every word must have the same length
+func NewSolver(guesses []string, answers []string) (*Solver, error) {
+	if len(answers) == 0 {
+		return nil, fmt.Errorf("no answers to solve for")
+	}
+	length := utf8.RuneCountInString(answers[0])
+	if length < 1 || length > MaxLength {
+		return nil, fmt.Errorf("words must have between 1 and %d letters", MaxLength)
+	}
+	all := slices.Concat(guesses, answers)
+	for i, word := range all {
+		all[i] = strings.ToLower(word)
+		if utf8.RuneCountInString(word) != length {
+			return nil, fmt.Errorf("%q does not have %d letters", word, length)
+		}
+	}
+	slices.Sort(all)
+	all = slices.Compact(all)
+
+	s := Solver{
+		length:  length,
+		guesses: all,
+		best:    map[string]int{},
+		counts:  make([]int, patterns(length)),
+	}
+	for _, word := range all {
+		s.runes = append(s.runes, []rune(word))
+	}
+	for _, answer := range answers {
+		i, _ := slices.BinarySearch(all, strings.ToLower(answer))
+		s.answers = append(s.answers, i)
+	}
+	slices.Sort(s.answers)
+	s.answers = slices.Compact(s.answers)
+	return &s, nil
+}
+
+// Number of letters of every word
+func (s *Solver) Length() int {
+	return s.length
+}
+
+// Every answer, alphabetically
+func (s *Solver) Answers() []string {
+	answers := []string{}
+	for _, i := range s.answers {
+		answers = append(answers, s.guesses[i])
+	}
+	return answers
+}
+
+// The n opening guesses giving the most information about the answer, from best to worst
+func (s *Solver) Openers(n int) []Opener {
+	openers := []Opener{}
+	for i, word := range s.guesses {
+		openers = append(openers, Opener{Word: word, Entropy: s.entropy(i, s.answers)})
+	}
+	slices.SortStableFunc(openers, func(a, b Opener) int {
+		return cmp.Compare(b.Entropy, a.Entropy)
+	})
+	return openers[:min(n, len(openers))]
+}
+
+// Plays a game against the answer, which must be one of the solver's answers, until it is solved
+func (s *Solver) Play(answer string) (*Game, error) {
+	target, found := slices.BinarySearch(s.guesses, strings.ToLower(answer))
+	if !found || !slices.Contains(s.answers, target) {
+		return nil, fmt.Errorf("%q is not an answer", answer)
+	}
+	game := Game{Answer: s.guesses[target]}
+	candidates := s.answers
+	var path strings.Builder
+	for {
+		guess, ok := s.best[path.String()]
+		if !ok {
+			guess = s.bestGuess(candidates)
+			s.best[path.String()] = guess
+		}
+		feedback := score(s.runes[guess], s.runes[target])
+		game.Guesses = append(game.Guesses, s.guesses[guess])
+		game.Feedback = append(game.Feedback, feedback.Pattern(s.length))
+		if feedback.Solved(s.length) {
+			return &game, nil
+		}
+		fmt.Fprintf(&path, "%d,", feedback)
+		candidates = s.consistent(candidates, guess, feedback)
+	}
+}
+
+// The guess maximizing the entropy of the feedback over the candidates, preferring guesses that
+// could themselves be the answer, and then the alphabetically first
+func (s *Solver) bestGuess(candidates []int) int {
+	if len(candidates) <= 2 {
+		return candidates[0]
+	}
+	// no guess can distinguish more than every candidate
+	perfect := math.Log2(float64(len(candidates)))
+	best, bestEntropy := candidates[0], s.entropy(candidates[0], candidates)
+	for _, c := range candidates[1:] {
+		if bestEntropy >= perfect {
+			return best
+		}
+		if e := s.entropy(c, candidates); e > bestEntropy {
+			best, bestEntropy = c, e
+		}
+	}
+	for i := range s.guesses {
+		if bestEntropy >= perfect {
+			break
+		}
+		if e := s.entropy(i, candidates); e > bestEntropy+1e-9 {
+			best, bestEntropy = i, e
+		}
+	}
+	return best
+}
+
+// Shannon entropy in bits of the feedback of the guess over the candidates
+func (s *Solver) entropy(guess int, candidates []int) float64 {
+	for _, c := range candidates {
+		f := score(s.runes[guess], s.runes[c])
+		if s.counts[f] == 0 {
+			s.counted = append(s.counted, f)
+		}
+		s.counts[f]++
+	}
+	entropy := 0.0
+	for _, f := range s.counted {
+		p := float64(s.counts[f]) / float64(len(candidates))
+		entropy -= p * math.Log2(p)
+		s.counts[f] = 0
+	}
+	s.counted = s.counted[:0]
+	return entropy
+}
+
+// The candidates which would have given the feedback for the guess
+func (s *Solver) consistent(candidates []int, guess int, feedback Feedback) []int {
+	remaining := []int{}
+	for _, c := range candidates {
+		if score(s.runes[guess], s.runes[c]) == feedback {
+			remaining = append(remaining, c)
+		}
+	}
+	return remaining
+}
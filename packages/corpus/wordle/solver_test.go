@@ -0,0 +1,83 @@
+package wordle
+
+import (
+	"math"
+	"testing"
+)
+
+var testWords = []string{"abbey", "abide", "babes", "crane", "crate", "geese", "react", "slate", "speed", "those", "trace"}
+
+func newTestSolver(t *testing.T) *Solver {
+	t.Helper()
+	solver, err := NewSolver(testWords, testWords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return solver
+}
+
+func TestNewSolverRejectsMixedLengths(t *testing.T) {
+	if _, err := NewSolver([]string{"crane", "cranes"}, []string{"crane"}); err == nil {
+		t.Errorf("mixed word lengths accepted")
+	}
+	if _, err := NewSolver(testWords, nil); err == nil {
+		t.Errorf("no answers accepted")
+	}
+}
+
+func TestPlayReachesEveryAnswer(t *testing.T) {
+	solver := newTestSolver(t)
+	for _, answer := range solver.Answers() {
+		game, err := solver.Play(answer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(game.Guesses) > len(testWords) {
+			t.Errorf("%s took %d guesses", answer, len(game.Guesses))
+		}
+		if last := game.Guesses[len(game.Guesses)-1]; last != answer {
+			t.Errorf("game of %s ended with %s", answer, last)
+		}
+		for i, guess := range game.Guesses {
+			if want := Score(guess, answer).Pattern(5); game.Feedback[i] != want {
+				t.Errorf("feedback of %s against %s is %q, want %q", guess, answer, game.Feedback[i], want)
+			}
+		}
+	}
+	if _, err := solver.Play("zzzzz"); err == nil {
+		t.Errorf("played a word that is not an answer")
+	}
+}
+
+func TestOpenersByEntropy(t *testing.T) {
+	solver := newTestSolver(t)
+	openers := solver.Openers(len(testWords))
+	if len(openers) != len(testWords) {
+		t.Fatalf("got %d openers, want %d", len(openers), len(testWords))
+	}
+	for i, opener := range openers {
+		if want := feedbackEntropy(opener.Word); math.Abs(opener.Entropy-want) > 1e-9 {
+			t.Errorf("entropy of %s is %f, want %f", opener.Word, opener.Entropy, want)
+		}
+		if i > 0 && opener.Entropy > openers[i-1].Entropy {
+			t.Errorf("%s (%f) is ordered after %s (%f)", opener.Word, opener.Entropy, openers[i-1].Word, openers[i-1].Entropy)
+		}
+	}
+	if top := solver.Openers(3); len(top) != 3 || top[0] != openers[0] {
+		t.Errorf("top 3 openers are %v", top)
+	}
+}
+
+// Entropy in bits of the feedback of the guess over the test words
+func feedbackEntropy(guess string) float64 {
+	counts := map[Feedback]int{}
+	for _, answer := range testWords {
+		counts[Score(guess, answer)]++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(len(testWords))
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
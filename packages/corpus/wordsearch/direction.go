@@ -0,0 +1,78 @@
+package wordsearch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Step from one letter of a word to the next, in columns and rows
+type Direction struct {
+	DX int
+	DY int
+}
+
+var (
+	Direction_Right     = Direction{1, 0}
+	Direction_Down      = Direction{0, 1}
+	Direction_DownRight = Direction{1, 1}
+	Direction_UpRight   = Direction{1, -1}
+	Direction_Left      = Direction{-1, 0}
+	Direction_Up        = Direction{0, -1}
+	Direction_UpLeft    = Direction{-1, -1}
+	Direction_DownLeft  = Direction{-1, 1}
+)
+
+// Names of the directions, as accepted by ParseDirections
+var directionNames = map[string]Direction{
+	"right":      Direction_Right,
+	"down":       Direction_Down,
+	"down-right": Direction_DownRight,
+	"up-right":   Direction_UpRight,
+	"left":       Direction_Left,
+	"up":         Direction_Up,
+	"up-left":    Direction_UpLeft,
+	"down-left":  Direction_DownLeft,
+}
+
+// Sets of directions for increasingly hard puzzles, as accepted by ParseDirections
+var directionPresets = map[string][]Direction{
+	// Reading forwards across and down
+	"easy": {Direction_Right, Direction_Down},
+	// Reading forwards, including diagonally
+	"medium": {Direction_Right, Direction_Down, Direction_DownRight, Direction_UpRight},
+	// Every direction, including backwards
+	"hard": {Direction_Right, Direction_Down, Direction_DownRight, Direction_UpRight,
+		Direction_Left, Direction_Up, Direction_UpLeft, Direction_DownLeft},
+}
+
+// Name of the direction, e.g. "down-right"
+func (d Direction) String() string {
+	for name, direction := range directionNames {
+		if direction == d {
+			return name
+		}
+	}
+	return fmt.Sprintf("(%d,%d)", d.DX, d.DY)
+}
+
+// Name of the direction, as it is written in JSON
+func (d Direction) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// Parses either a preset ("easy", "medium" or "hard") or a comma-separated list of directions
+// like "right,down,down-right"
+func ParseDirections(s string) ([]Direction, error) {
+	if preset, ok := directionPresets[s]; ok {
+		return preset, nil
+	}
+	directions := []Direction{}
+	for _, name := range strings.Split(s, ",") {
+		direction, ok := directionNames[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("invalid direction %q", name)
+		}
+		directions = append(directions, direction)
+	}
+	return directions, nil
+}
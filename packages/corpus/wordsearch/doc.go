@@ -0,0 +1,3 @@
+// Word search grids: words hidden along lines of a grid in a set of directions, crossing where
+// their letters agree, with the rest of the grid filled by letters that do not spell extra words
+package wordsearch
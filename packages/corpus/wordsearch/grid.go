@@ -0,0 +1,236 @@
+package wordsearch
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math/rand"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/digitaltembo/motli/packages/corpus/lexicon"
+)
+
+// Shortest extra dictionary word that the filler letters are checked for
+const MinAccidentalLength = 4
+
+// Number of times the filler letters of extra words are redrawn before giving up on removing them
+const refills = 100
+
+// A word along a line of the grid, starting at column X and row Y
+type Placement struct {
+	Word      string    `json:"word"`
+	X         int       `json:"x"`
+	Y         int       `json:"y"`
+	Direction Direction `json:"direction"`
+}
+
+// A word search puzzle
+type Puzzle struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	// letters of the grid in row-major order, lower-case
+	Cells []string `json:"cells"`
+	// Every word hidden in the grid
+	Words []Placement `json:"words"`
+	// Words which did not fit in the grid
+	Skipped []string `json:"skipped"`
+	// Dictionary words which can be found in the grid but were not meant to be hidden, which only
+	// remain if they are made up of the letters of hidden words or could not be redrawn away
+	Accidental []Placement `json:"accidental"`
+}
+
+// The letter at column x and row y
+func (p *Puzzle) Cell(x int, y int) string {
+	return p.Cells[y*p.Width+x]
+}
+
+// One row of the grid per line, in upper-case
+func (p *Puzzle) String() string {
+	var s strings.Builder
+	for y := range p.Height {
+		s.WriteString(strings.ToUpper(strings.Join(p.Cells[y*p.Width:(y+1)*p.Width], " ")))
+		s.WriteByte('\n')
+	}
+	return s.String()
+}
+
+// Hides the words in a width by height grid along the directions, longest first, crossing other
+// words where the letters agree as often as possible, and skipping words which do not fit. The rest of
+// the grid is filled with letters drawn at random in proportion to the counts of the filler tiles
+// (ignoring multi-letter tiles), which are redrawn wherever they complete an extra word of the lexicon
+// of at least 4 letters in one of the directions
+func Generate(rng *rand.Rand, words []string, width int, height int, directions []Direction, filler map[string]int, lex *lexicon.Lexicon) (*Puzzle, error) {
+	if width < 1 || height < 1 || len(directions) == 0 {
+		return nil, fmt.Errorf("grid must have a positive size and at least one direction")
+	}
+	letters, weights, total := []string{}, []int{}, 0
+	for _, tile := range slices.Sorted(maps.Keys(filler)) {
+		if utf8.RuneCountInString(tile) == 1 && filler[tile] > 0 {
+			letters = append(letters, strings.ToLower(tile))
+			total += filler[tile]
+			weights = append(weights, total)
+		}
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("no single-letter filler tiles")
+	}
+	draw := func() string {
+		n := rng.Intn(total)
+		i, _ := slices.BinarySearch(weights, n+1)
+		return letters[i]
+	}
+
+	p := Puzzle{Width: width, Height: height, Cells: make([]string, width*height), Words: []Placement{}, Skipped: []string{}}
+	for _, word := range placementOrder(words) {
+		if placement, ok := p.place(rng, word, directions); ok {
+			p.Words = append(p.Words, placement)
+		} else {
+			p.Skipped = append(p.Skipped, word)
+		}
+	}
+
+	fill := []int{}
+	for i, cell := range p.Cells {
+		if cell == "" {
+			fill = append(fill, i)
+			p.Cells[i] = draw()
+		}
+	}
+	isFill := map[int]bool{}
+	for _, i := range fill {
+		isFill[i] = true
+	}
+	for range refills {
+		p.Accidental = p.accidental(lex, directions)
+		redrawn := false
+		for _, placement := range p.Accidental {
+			for _, i := range p.cells(placement) {
+				if isFill[i] {
+					p.Cells[i] = draw()
+					redrawn = true
+				}
+			}
+		}
+		if !redrawn {
+			break
+		}
+	}
+	p.Accidental = p.accidental(lex, directions)
+	return &p, nil
+}
+
+// The distinct lower-case words, from longest to shortest and then alphabetically
+func placementOrder(words []string) []string {
+	ordered := []string{}
+	for _, word := range words {
+		ordered = append(ordered, strings.ToLower(word))
+	}
+	slices.SortFunc(ordered, func(a, b string) int {
+		if c := cmp.Compare(utf8.RuneCountInString(b), utf8.RuneCountInString(a)); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	return slices.Compact(ordered)
+}
+
+// Writes the word into the grid at the position crossing the most letters already in the grid,
+// breaking ties at random, unless it does not fit anywhere. A word is never placed entirely over
+// other words' letters, as it would then be hidden in them
+func (p *Puzzle) place(rng *rand.Rand, word string, directions []Direction) (Placement, bool) {
+	runes := []rune(word)
+	best, bestOverlap := Placement{}, -1
+	for _, i := range rng.Perm(p.Width * p.Height * len(directions)) {
+		d := directions[i%len(directions)]
+		x, y := (i/len(directions))%p.Width, (i/len(directions))/p.Width
+		overlap := p.overlap(runes, x, y, d)
+		if overlap > bestOverlap && overlap < len(runes) {
+			best, bestOverlap = Placement{Word: word, X: x, Y: y, Direction: d}, overlap
+		}
+	}
+	if bestOverlap < 0 {
+		return Placement{}, false
+	}
+	for i, cell := range p.cells(best) {
+		p.Cells[cell] = string(runes[i])
+	}
+	return best, true
+}
+
+// Number of letters of the word starting at column x and row y that are already in the grid,
+// or -1 if the word goes off the grid or disagrees with a letter already in the grid
+func (p *Puzzle) overlap(runes []rune, x int, y int, d Direction) int {
+	endX, endY := x+d.DX*(len(runes)-1), y+d.DY*(len(runes)-1)
+	if endX < 0 || endX >= p.Width || endY < 0 || endY >= p.Height {
+		return -1
+	}
+	overlap := 0
+	for i, r := range runes {
+		switch p.Cell(x+d.DX*i, y+d.DY*i) {
+		case "":
+		case string(r):
+			overlap++
+		default:
+			return -1
+		}
+	}
+	return overlap
+}
+
+// Indexes into the cells of each letter of the placement
+func (p *Puzzle) cells(placement Placement) []int {
+	cells := []int{}
+	for i := range utf8.RuneCountInString(placement.Word) {
+		cells = append(cells, (placement.Y+placement.Direction.DY*i)*p.Width+placement.X+placement.Direction.DX*i)
+	}
+	return cells
+}
+
+// Every word of the lexicon of at least 4 letters along one of the directions, except for those
+// lying within a hidden word
+func (p *Puzzle) accidental(lex *lexicon.Lexicon, directions []Direction) []Placement {
+	// which hidden words cover each cell
+	covering := make([][]int, len(p.Cells))
+	for w, placement := range p.Words {
+		for _, cell := range p.cells(placement) {
+			covering[cell] = append(covering[cell], w)
+		}
+	}
+
+	found := []Placement{}
+	for start := range p.Cells {
+		for _, d := range directions {
+			node := lex.Dawg.Root()
+			word := []rune{}
+			for x, y := start%p.Width, start/p.Width; x >= 0 && x < p.Width && y >= 0 && y < p.Height; x, y = x+d.DX, y+d.DY {
+				var ok bool
+				r, _ := utf8.DecodeRuneInString(p.Cell(x, y))
+				if node, ok = lex.Dawg.Next(node, r); !ok {
+					break
+				}
+				word = append(word, r)
+				if len(word) < MinAccidentalLength || !lex.Dawg.IsTerminal(node) {
+					continue
+				}
+				placement := Placement{Word: string(word), X: start % p.Width, Y: start / p.Width, Direction: d}
+				if !p.within(placement, covering) {
+					found = append(found, placement)
+				}
+			}
+		}
+	}
+	return found
+}
+
+// Whether every letter of the placement is covered by the same hidden word
+func (p *Puzzle) within(placement Placement, covering [][]int) bool {
+	cells := p.cells(placement)
+	for _, w := range covering[cells[0]] {
+		if !slices.ContainsFunc(cells, func(cell int) bool { return !slices.Contains(covering[cell], w) }) {
+			return true
+		}
+	}
+	return false
+}